@@ -1,6 +1,10 @@
 package config
 
 import (
+	"log"
+	"strconv"
+	"strings"
+
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/providers/confmap"
 	"github.com/knadh/koanf/providers/env"
@@ -10,27 +14,135 @@ var k = koanf.New(".")
 
 // Config contains configuration parameters for this program
 type Config struct {
-	HubAPIURL                      string
-	ConnectTimeout, RequestTimeout int
-	UseSSL                         bool
+	HubAPIURL                                                 string
+	ListenAddress, APIPath                                    string
+	ListenPort, HTTPRedirectPort                              int
+	TLSCertFile, TLSKeyFile, TLSMinVersion                    string
+	ConnectTimeout, RequestTimeout                            int
+	ServerConnectTimeouts, ServerRequestTimeouts              map[string]int
+	MethodRequestTimeouts                                     map[string]int
+	UseSSL                                                    bool
+	MaxIdleConns, MaxIdleConnsPerServer, IdleConnTimeout      int
+	MaxConcurrentServerCalls                                  int
+	ServerCallMaxRetries                                      int
+	ServerCallRetryBackoff, ServerCallRetryMaxBackoff         int
+	ServerCallRetryDenylist                                   []string
+	CircuitBreakerFailureThreshold, CircuitBreakerOpenTimeout int
+	JobRetention                                              int
+	SessionTTL, SessionIdleTimeout, SessionReaperInterval     int
+	SessionKeepAliveInterval                                  int
+	SessionStorage, SessionStoragePath, SessionStorageKeyPath string
+	LogFormat                                                 string
+	ShutdownTimeout                                           int
+	ShutdownLogout                                            bool
 }
 
 // NewConfig reads configuration from environment variables
 func NewConfig() *Config {
 
 	k.Load(confmap.Provider(map[string]interface{}{
-		"HUB_API_URL":           "http://localhost/rpc/api",
-		"HUB_CONNECT_TIMEOUT":   10,
-		"HUB_REQUEST_TIMEOUT":   10,
-		"HUB_CONNECT_USING_SSL": false,
+		"HUB_API_URL":                           "http://localhost/rpc/api",
+		"HUB_LISTEN_ADDRESS":                    "",
+		"HUB_LISTEN_PORT":                       2830,
+		"HUB_API_PATH":                          "/hub/rpc/api",
+		"HUB_TLS_CERT_FILE":                     "",
+		"HUB_TLS_KEY_FILE":                      "",
+		"HUB_TLS_MIN_VERSION":                   "1.2",
+		"HUB_HTTP_REDIRECT_PORT":                0,
+		"HUB_CONNECT_TIMEOUT":                   10,
+		"HUB_REQUEST_TIMEOUT":                   10,
+		"HUB_SERVER_CONNECT_TIMEOUTS":           "",
+		"HUB_SERVER_REQUEST_TIMEOUTS":           "",
+		"HUB_METHOD_REQUEST_TIMEOUTS":           "",
+		"HUB_CONNECT_USING_SSL":                 false,
+		"HUB_MAX_IDLE_CONNS":                    100,
+		"HUB_MAX_IDLE_CONNS_PER_SERVER":         2,
+		"HUB_IDLE_CONN_TIMEOUT":                 90,
+		"HUB_MAX_CONCURRENT_SERVER_CALLS":       100,
+		"HUB_SERVER_CALL_MAX_RETRIES":           2,
+		"HUB_SERVER_CALL_RETRY_BACKOFF":         500,
+		"HUB_SERVER_CALL_RETRY_MAX_BACKOFF":     5000,
+		"HUB_SERVER_CALL_RETRY_DENYLIST":        "*.add*,*.create*,*.delete*,*.remove*,*.schedule*,*.set*,*.update*",
+		"HUB_CIRCUIT_BREAKER_FAILURE_THRESHOLD": 5,
+		"HUB_CIRCUIT_BREAKER_OPEN_TIMEOUT":      30,
+		"HUB_JOB_RETENTION":                     3600,
+		"HUB_SESSION_TTL":                       86400,
+		"HUB_SESSION_IDLE_TIMEOUT":              3600,
+		"HUB_SESSION_REAPER_INTERVAL":           60,
+		"HUB_SESSION_KEEPALIVE_INTERVAL":        0,
+		"HUB_SESSION_STORAGE":                   "memory",
+		"HUB_SESSION_STORAGE_PATH":              "/var/lib/hub/sessions.db",
+		"HUB_SESSION_STORAGE_KEY_PATH":          "/var/lib/hub/sessions.key",
+		"HUB_LOG_FORMAT":                        "logfmt",
+		"HUB_SHUTDOWN_TIMEOUT":                  30,
+		"HUB_SHUTDOWN_LOGOUT":                   false,
 	}, "."), nil)
 
 	k.Load(env.Provider("HUB_", ".", nil), nil)
 
 	return &Config{
-		HubAPIURL:      k.String("HUB_API_URL"),
-		ConnectTimeout: k.Int("HUB_CONNECT_TIMEOUT"),
-		RequestTimeout: k.Int("HUB_REQUEST_TIMEOUT"),
-		UseSSL:         k.Bool("HUB_CONNECT_USING_SSL"),
+		HubAPIURL:                      k.String("HUB_API_URL"),
+		ListenAddress:                  k.String("HUB_LISTEN_ADDRESS"),
+		ListenPort:                     k.Int("HUB_LISTEN_PORT"),
+		APIPath:                        k.String("HUB_API_PATH"),
+		TLSCertFile:                    k.String("HUB_TLS_CERT_FILE"),
+		TLSKeyFile:                     k.String("HUB_TLS_KEY_FILE"),
+		TLSMinVersion:                  k.String("HUB_TLS_MIN_VERSION"),
+		HTTPRedirectPort:               k.Int("HUB_HTTP_REDIRECT_PORT"),
+		ConnectTimeout:                 k.Int("HUB_CONNECT_TIMEOUT"),
+		RequestTimeout:                 k.Int("HUB_REQUEST_TIMEOUT"),
+		ServerConnectTimeouts:          parseTimeouts("HUB_SERVER_CONNECT_TIMEOUTS"),
+		ServerRequestTimeouts:          parseTimeouts("HUB_SERVER_REQUEST_TIMEOUTS"),
+		MethodRequestTimeouts:          parseTimeouts("HUB_METHOD_REQUEST_TIMEOUTS"),
+		UseSSL:                         k.Bool("HUB_CONNECT_USING_SSL"),
+		MaxIdleConns:                   k.Int("HUB_MAX_IDLE_CONNS"),
+		MaxIdleConnsPerServer:          k.Int("HUB_MAX_IDLE_CONNS_PER_SERVER"),
+		IdleConnTimeout:                k.Int("HUB_IDLE_CONN_TIMEOUT"),
+		MaxConcurrentServerCalls:       k.Int("HUB_MAX_CONCURRENT_SERVER_CALLS"),
+		ServerCallMaxRetries:           k.Int("HUB_SERVER_CALL_MAX_RETRIES"),
+		ServerCallRetryBackoff:         k.Int("HUB_SERVER_CALL_RETRY_BACKOFF"),
+		ServerCallRetryMaxBackoff:      k.Int("HUB_SERVER_CALL_RETRY_MAX_BACKOFF"),
+		ServerCallRetryDenylist:        splitList(k.String("HUB_SERVER_CALL_RETRY_DENYLIST")),
+		CircuitBreakerFailureThreshold: k.Int("HUB_CIRCUIT_BREAKER_FAILURE_THRESHOLD"),
+		CircuitBreakerOpenTimeout:      k.Int("HUB_CIRCUIT_BREAKER_OPEN_TIMEOUT"),
+		JobRetention:                   k.Int("HUB_JOB_RETENTION"),
+		SessionTTL:                     k.Int("HUB_SESSION_TTL"),
+		SessionIdleTimeout:             k.Int("HUB_SESSION_IDLE_TIMEOUT"),
+		SessionReaperInterval:          k.Int("HUB_SESSION_REAPER_INTERVAL"),
+		SessionKeepAliveInterval:       k.Int("HUB_SESSION_KEEPALIVE_INTERVAL"),
+		SessionStorage:                 k.String("HUB_SESSION_STORAGE"),
+		SessionStoragePath:             k.String("HUB_SESSION_STORAGE_PATH"),
+		SessionStorageKeyPath:          k.String("HUB_SESSION_STORAGE_KEY_PATH"),
+		LogFormat:                      k.String("HUB_LOG_FORMAT"),
+		ShutdownTimeout:                k.Int("HUB_SHUTDOWN_TIMEOUT"),
+		ShutdownLogout:                 k.Bool("HUB_SHUTDOWN_LOGOUT"),
+	}
+}
+
+// splitList parses a comma separated list, ignoring empty items
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseTimeouts parses a comma separated list of key=seconds items
+func parseTimeouts(name string) map[string]int {
+	timeouts := make(map[string]int)
+	for _, item := range splitList(k.String(name)) {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalf("Invalid %v item, expected key=seconds: %v", name, item)
+		}
+		timeout, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || timeout <= 0 {
+			log.Fatalf("Invalid %v timeout for %v: %v", name, parts[0], parts[1])
+		}
+		timeouts[strings.TrimSpace(parts[0])] = timeout
 	}
+	return timeouts
 }
@@ -1,10 +1,10 @@
 package controller
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
 type ServerAuthenticationController struct {
@@ -20,14 +20,52 @@ type AttachToServersRequest struct {
 	HubSessionKey       string
 	ServerIDs           []int64
 	CredentialsByServer map[int64]*gateway.Credentials
+	Options             ServerAuthenticationOptions
+}
+
+//ServerAuthenticationOptions are optionally passed by the caller as a struct after all the other parameters
+//of the methods which attach to or detach from servers
+type ServerAuthenticationOptions struct {
+	LegacyFailedResponses bool
 }
 
 func (h *ServerAuthenticationController) AttachToServers(r *http.Request, args *AttachToServersRequest, reply *struct{ Data *MulticastResponse }) error {
-	attachToServersResponse, err := h.serverAuthenticator.AttachToServers(args.HubSessionKey, args.ServerIDs, args.CredentialsByServer)
+	attachToServersResponse, err := h.serverAuthenticator.AttachToServers(r.Context(), args.HubSessionKey, args.ServerIDs, args.CredentialsByServer)
+	if err != nil {
+		logging.Error(r.Context(), "Login error", "error", err)
+		return err
+	}
+	reply.Data = h.responseTransformer(attachToServersResponse, args.Options.LegacyFailedResponses)
+	return nil
+}
+
+type DetachFromServersRequest struct {
+	HubSessionKey string
+	ServerIDs     []int64
+	Options       ServerAuthenticationOptions
+}
+
+func (h *ServerAuthenticationController) DetachFromServers(r *http.Request, args *DetachFromServersRequest, reply *struct{ Data *MulticastResponse }) error {
+	detachFromServersResponse, err := h.serverAuthenticator.DetachFromServers(r.Context(), args.HubSessionKey, args.ServerIDs)
+	if err != nil {
+		logging.Error(r.Context(), "Error ocurred while detaching from servers", "error", err)
+		return err
+	}
+	reply.Data = h.responseTransformer(detachFromServersResponse, args.Options.LegacyFailedResponses)
+	return nil
+}
+
+type RetryFailedAttachmentsRequest struct {
+	HubSessionKey string
+	Options       ServerAuthenticationOptions
+}
+
+func (h *ServerAuthenticationController) RetryFailedAttachments(r *http.Request, args *RetryFailedAttachmentsRequest, reply *struct{ Data *MulticastResponse }) error {
+	retryResponse, err := h.serverAuthenticator.RetryFailedAttachments(r.Context(), args.HubSessionKey)
 	if err != nil {
-		log.Printf("Login error: %v", err)
+		logging.Error(r.Context(), "Error ocurred while retrying failed attachments", "error", err)
 		return err
 	}
-	reply.Data = h.responseTransformer(attachToServersResponse)
+	reply.Data = h.responseTransformer(retryResponse, args.Options.LegacyFailedResponses)
 	return nil
 }
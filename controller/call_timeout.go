@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+//callTimeoutHeader lets the callers of unicast and multicast methods request a deadline, in seconds, for the whole call
+const callTimeoutHeader = "X-Hub-Timeout"
+
+//callContext returns the context of the request bounded by the deadline requested by the caller, if any.
+//The deadline can only make the timeouts configured for the servers tighter, never extend them.
+func callContext(r *http.Request, timeout int) (context.Context, context.CancelFunc, error) {
+	if header := r.Header.Get(callTimeoutHeader); header != "" {
+		headerTimeout, err := strconv.Atoi(header)
+		if err != nil || headerTimeout < 0 {
+			logging.Error(r.Context(), "Error ocurred when parsing header", "header", callTimeoutHeader, "value", header)
+			return nil, nil, FaultInvalidParams
+		}
+		if timeout == 0 || (headerTimeout > 0 && headerTimeout < timeout) {
+			timeout = headerTimeout
+		}
+	}
+	if timeout == 0 {
+		ctx, cancel := context.WithCancel(r.Context())
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(timeout)*time.Second)
+	return ctx, cancel, nil
+}
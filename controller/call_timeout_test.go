@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_callContext(t *testing.T) {
+	tt := []struct {
+		name             string
+		header           string
+		timeout          int
+		expectedDeadline time.Duration
+		expectedErr      error
+	}{
+		{name: "callContext without_timeout"},
+		{name: "callContext configured_timeout", timeout: 30, expectedDeadline: 30 * time.Second},
+		{name: "callContext header_timeout", header: "20", expectedDeadline: 20 * time.Second},
+		{name: "callContext header_tightens_configured_timeout", header: "10", timeout: 30, expectedDeadline: 10 * time.Second},
+		{name: "callContext header_does_not_extend_configured_timeout", header: "60", timeout: 30, expectedDeadline: 30 * time.Second},
+		{name: "callContext zero_header_keeps_configured_timeout", header: "0", timeout: 30, expectedDeadline: 30 * time.Second},
+		{name: "callContext zero_header_without_timeout", header: "0"},
+		{name: "callContext negative_header", header: "-5", timeout: 30, expectedErr: FaultInvalidParams},
+		{name: "callContext invalid_header", header: "ten", timeout: 30, expectedErr: FaultInvalidParams},
+		{name: "callContext decimal_header", header: "1.5", expectedErr: FaultInvalidParams},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/hub/rpc/api", nil)
+			if tc.header != "" {
+				request.Header.Set(callTimeoutHeader, tc.header)
+			}
+
+			ctx, cancel, err := callContext(request, tc.timeout)
+
+			if err != tc.expectedErr {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", err, tc.expectedErr)
+			}
+			if err != nil {
+				return
+			}
+			defer cancel()
+			deadline, hasDeadline := ctx.Deadline()
+			if hasDeadline != (tc.expectedDeadline > 0) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", hasDeadline, tc.expectedDeadline > 0)
+			}
+			if !hasDeadline {
+				return
+			}
+			if remaining := time.Until(deadline); remaining > tc.expectedDeadline || remaining < tc.expectedDeadline-time.Second {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", remaining, tc.expectedDeadline)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+type CircuitBreakerController struct {
+	circuitBreakerInfoRetriever gateway.CircuitBreakerInfoRetriever
+}
+
+func NewCircuitBreakerController(circuitBreakerInfoRetriever gateway.CircuitBreakerInfoRetriever) *CircuitBreakerController {
+	return &CircuitBreakerController{circuitBreakerInfoRetriever}
+}
+
+func (h *CircuitBreakerController) ListServerCircuitBreakers(r *http.Request, args *struct{ HubSessionKey string }, reply *struct {
+	Data []gateway.ServerCircuitBreakerState
+}) error {
+	states, err := h.circuitBreakerInfoRetriever.ListServerCircuitBreakers(r.Context(), args.HubSessionKey)
+	if err != nil {
+		logging.Error(r.Context(), "Error ocurred while retrieving circuit breakers", "error", err)
+		return err
+	}
+	reply.Data = states
+	return nil
+}
@@ -1,9 +1,15 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	"fmt"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
 )
 
+//The faults generated by the Hub XMLRPC API itself have codes from -32768 to -32000.
+//Faults returned by the Hub or the peripheral servers are reported with their original code and message.
 var (
 	FaultInvalidParams        = FaultError{Code: -32602, Message: "Invalid Method Parameters"}
 	FaultWrongArgumentsNumber = FaultError{Code: -32602, Message: "Wrong Arguments Number"}
@@ -11,14 +17,109 @@ var (
 	FaultApplicationError     = FaultError{Code: -32500, Message: "Application Error"}
 	FaultSystemError          = FaultError{Code: -32400, Message: "System Error"}
 	FaultDecode               = FaultError{Code: -32700, Message: "Parsing error: not well formed"}
+	FaultInvalidRequest       = FaultError{Code: -32600, Message: "Invalid Request"}
+	FaultMethodNotFound       = FaultError{Code: -32601, Message: "Method not found"}
+	FaultInvalidSessionKey    = FaultError{Code: -32001, Message: "Authentication error: provided session key is invalid"}
+	FaultJobNotFound          = FaultError{Code: -32002, Message: "Job not found"}
+	FaultServerUnavailable    = FaultError{Code: -32003, Message: "Server unavailable"}
+	FaultServerCallFailed     = FaultError{Code: -32004, Message: "Server call failed"}
+	FaultServerNotAttached    = FaultError{Code: -32005, Message: "Server not attached"}
 	FaultInvalidCredentials   = FaultError{Code: 2950, Message: "Either the password or username is incorrect"}
 )
 
 type FaultError struct {
-	Code    int    `xmlrpc:"faultCode"`
-	Message string `xmlrpc:"faultString"`
+	Code    int    `xmlrpc:"faultCode" json:"faultCode"`
+	Message string `xmlrpc:"faultString" json:"faultString"`
 }
 
 func (f FaultError) Error() string {
 	return fmt.Sprintf("%d: %s", f.Code, f.Message)
 }
+
+//serverFault is implemented by the faults returned by the Hub or the peripheral servers
+type serverFault interface {
+	FaultCode() int
+	FaultString() string
+}
+
+//transientError is implemented by the errors caused by network failures or HTTP server errors
+type transientError interface {
+	Transient() bool
+}
+
+//timeoutError is implemented by the transient errors which may have been caused by a timeout
+type timeoutError interface {
+	Timeout() bool
+}
+
+//Categories of the errors of the calls to the peripheral servers
+const (
+	ErrorCategoryTimeout        = "timeout"
+	ErrorCategoryConnection     = "connection"
+	ErrorCategoryAuthentication = "authentication"
+	ErrorCategoryUnavailable    = "unavailable"
+	ErrorCategoryNotAttached    = "unattached"
+	ErrorCategoryCancelled      = "cancelled"
+	ErrorCategoryFault          = "fault"
+	ErrorCategoryOther          = "error"
+)
+
+//ErrorCategory classifies the error of a call to a peripheral server
+func ErrorCategory(err error) string {
+	var fault FaultError
+	var serverFault serverFault
+	var transientErr transientError
+	var timeoutErr timeoutError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &timeoutErr) && timeoutErr.Timeout():
+		return ErrorCategoryTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorCategoryCancelled
+	case errors.Is(err, gateway.ErrInvalidHubSessionKey):
+		return ErrorCategoryAuthentication
+	case errors.Is(err, gateway.ErrServerUnavailable):
+		return ErrorCategoryUnavailable
+	case errors.Is(err, gateway.ErrServerNotAttached):
+		return ErrorCategoryNotAttached
+	case errors.As(err, &fault):
+		return faultCategory(fault.Code)
+	case errors.As(err, &serverFault):
+		return faultCategory(serverFault.FaultCode())
+	case errors.As(err, &transientErr) && transientErr.Transient():
+		return ErrorCategoryConnection
+	}
+	return ErrorCategoryOther
+}
+
+func faultCategory(code int) string {
+	if code == FaultInvalidCredentials.Code || code == FaultInvalidSessionKey.Code {
+		return ErrorCategoryAuthentication
+	}
+	return ErrorCategoryFault
+}
+
+//ToFaultError returns the fault reported to the caller for an error returned by a service method
+func ToFaultError(err error) FaultError {
+	var fault FaultError
+	var serverFault serverFault
+	var transientErr transientError
+	switch {
+	case errors.As(err, &fault):
+		return fault
+	case errors.As(err, &serverFault):
+		return FaultError{serverFault.FaultCode(), serverFault.FaultString()}
+	case errors.Is(err, gateway.ErrInvalidHubSessionKey):
+		return FaultInvalidSessionKey
+	case errors.Is(err, gateway.ErrJobNotFound):
+		return FaultError{FaultJobNotFound.Code, err.Error()}
+	case errors.Is(err, gateway.ErrServerUnavailable):
+		return FaultError{FaultServerUnavailable.Code, err.Error()}
+	case errors.Is(err, gateway.ErrServerNotAttached):
+		return FaultError{FaultServerNotAttached.Code, err.Error()}
+	case errors.As(err, &transientErr) && transientErr.Transient():
+		return FaultError{FaultServerCallFailed.Code, FaultServerCallFailed.Message + ": " + err.Error()}
+	}
+	fault = FaultApplicationError
+	fault.Message += fmt.Sprintf(": %v", err)
+	return fault
+}
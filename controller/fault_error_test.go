@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+)
+
+type mockServerFault struct{}
+
+func (m *mockServerFault) Error() string       { return "Fault(-210): No such system" }
+func (m *mockServerFault) FaultCode() int      { return -210 }
+func (m *mockServerFault) FaultString() string { return "No such system" }
+
+type mockTransientError struct{}
+
+func (m *mockTransientError) Error() string   { return "request timeout: i/o timeout" }
+func (m *mockTransientError) Transient() bool { return true }
+
+type mockTimeoutError struct{ mockTransientError }
+
+func (m *mockTimeoutError) Timeout() bool { return true }
+
+func Test_ToFaultError(t *testing.T) {
+	tt := []struct {
+		name          string
+		err           error
+		expectedFault FaultError
+	}{
+		{name: "ToFaultError fault_error", err: FaultInvalidParams, expectedFault: FaultInvalidParams},
+		{name: "ToFaultError server_fault", err: new(mockServerFault), expectedFault: FaultError{-210, "No such system"}},
+		{name: "ToFaultError invalid_session_key", err: gateway.ErrInvalidHubSessionKey, expectedFault: FaultInvalidSessionKey},
+		{name: "ToFaultError job_not_found",
+			err:           fmt.Errorf("%w: %v", gateway.ErrJobNotFound, "jobID"),
+			expectedFault: FaultError{-32002, "Job not found: jobID"}},
+		{name: "ToFaultError server_unavailable",
+			err:           fmt.Errorf("%w: circuit breaker is open", gateway.ErrServerUnavailable),
+			expectedFault: FaultError{-32003, "Server unavailable: circuit breaker is open"}},
+		{name: "ToFaultError server_not_attached",
+			err:           fmt.Errorf("%w: login_error", gateway.ErrServerNotAttached),
+			expectedFault: FaultError{-32005, "Server not attached: login_error"}},
+		{name: "ToFaultError transient_error",
+			err:           new(mockTransientError),
+			expectedFault: FaultError{-32004, "Server call failed: request timeout: i/o timeout"}},
+		{name: "ToFaultError other_error", err: errors.New("call_error"), expectedFault: FaultError{-32500, "Application Error: call_error"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			fault := ToFaultError(tc.err)
+			if fault != tc.expectedFault {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", fault, tc.expectedFault)
+			}
+		})
+	}
+}
+
+func Test_ErrorCategory(t *testing.T) {
+	tt := []struct {
+		name             string
+		err              error
+		expectedCategory string
+	}{
+		{name: "ErrorCategory timeout", err: new(mockTimeoutError), expectedCategory: ErrorCategoryTimeout},
+		{name: "ErrorCategory deadline_exceeded", err: context.DeadlineExceeded, expectedCategory: ErrorCategoryTimeout},
+		{name: "ErrorCategory cancelled", err: context.Canceled, expectedCategory: ErrorCategoryCancelled},
+		{name: "ErrorCategory connection", err: new(mockTransientError), expectedCategory: ErrorCategoryConnection},
+		{name: "ErrorCategory invalid_session_key", err: gateway.ErrInvalidHubSessionKey, expectedCategory: ErrorCategoryAuthentication},
+		{name: "ErrorCategory invalid_credentials", err: fmt.Errorf("login failed: %w", FaultInvalidCredentials), expectedCategory: ErrorCategoryAuthentication},
+		{name: "ErrorCategory server_unavailable", err: fmt.Errorf("%w: circuit breaker is open", gateway.ErrServerUnavailable), expectedCategory: ErrorCategoryUnavailable},
+		{name: "ErrorCategory server_not_attached", err: fmt.Errorf("%w: login_error", gateway.ErrServerNotAttached), expectedCategory: ErrorCategoryNotAttached},
+		{name: "ErrorCategory server_fault", err: new(mockServerFault), expectedCategory: ErrorCategoryFault},
+		{name: "ErrorCategory other_error", err: errors.New("call_error"), expectedCategory: ErrorCategoryOther},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			category := ErrorCategory(tc.err)
+			if category != tc.expectedCategory {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", category, tc.expectedCategory)
+			}
+		})
+	}
+}
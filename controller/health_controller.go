@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+)
+
+//readinessCheckTimeout bounds the time spent probing the Hub and the peripheral servers
+const readinessCheckTimeout = 5 * time.Second
+
+//hubSessionKeyHeader authenticates the requests for the reachability of the peripheral servers,
+//which is only reported for the servers attached to the given hub session
+const hubSessionKeyHeader = "X-Hub-Session-Key"
+
+//HealthController serves the HTTP endpoints probed by load balancers and monitoring systems
+type HealthController struct {
+	readinessChecker gateway.ReadinessChecker
+}
+
+func NewHealthController(readinessChecker gateway.ReadinessChecker) *HealthController {
+	return &HealthController{readinessChecker}
+}
+
+type healthResponse struct {
+	Status  string                    `json:"status"`
+	Checks  []healthCheckResponse     `json:"checks"`
+	Servers []serverReachabilityEntry `json:"servers,omitempty"`
+}
+
+type healthCheckResponse struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type serverReachabilityEntry struct {
+	ServerID int64  `json:"serverId"`
+	Endpoint string `json:"endpoint"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+//Healthz reports that the process is alive and serving HTTP requests
+func (h *HealthController) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeHealthResponse(w, http.StatusOK, &healthResponse{
+		Status: gateway.HealthCheckOK,
+		Checks: []healthCheckResponse{{Name: "process", Status: gateway.HealthCheckOK}},
+	})
+}
+
+//Readyz reports whether the service can serve requests. If the servers query parameter is true, the reachability
+//of the peripheral servers attached to the hub session passed in the X-Hub-Session-Key header is included.
+func (h *HealthController) Readyz(w http.ResponseWriter, r *http.Request) {
+	includeServers, _ := strconv.ParseBool(r.URL.Query().Get("servers"))
+	hubSessionKey := ""
+	if includeServers {
+		if hubSessionKey = r.Header.Get(hubSessionKeyHeader); hubSessionKey == "" {
+			writeUnauthorizedResponse(w)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+	defer cancel()
+	report, err := h.readinessChecker.CheckReadiness(ctx, hubSessionKey)
+	if err != nil {
+		writeUnauthorizedResponse(w)
+		return
+	}
+
+	response := &healthResponse{Status: gateway.HealthCheckOK, Checks: make([]healthCheckResponse, 0, len(report.Checks))}
+	status := http.StatusOK
+	if !report.Ready {
+		response.Status = gateway.HealthCheckFailed
+		status = http.StatusServiceUnavailable
+	}
+	for _, check := range report.Checks {
+		response.Checks = append(response.Checks, healthCheckResponse{check.Name, check.Status, check.ErrorMessage})
+	}
+	for _, server := range report.Servers {
+		response.Servers = append(response.Servers, serverReachabilityEntry{server.ServerID, server.Endpoint, server.Status, server.ErrorMessage})
+	}
+	writeHealthResponse(w, status, response)
+}
+
+func writeUnauthorizedResponse(w http.ResponseWriter) {
+	writeHealthResponse(w, http.StatusUnauthorized, &healthResponse{
+		Status: gateway.HealthCheckFailed,
+		Checks: []healthCheckResponse{{Name: "authentication", Status: gateway.HealthCheckFailed, Error: gateway.ErrInvalidHubSessionKey.Error()}},
+	})
+}
+
+func writeHealthResponse(w http.ResponseWriter, status int, response *healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
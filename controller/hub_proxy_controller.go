@@ -1,10 +1,10 @@
 package controller
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
 type HubProxyController struct {
@@ -17,9 +17,9 @@ type ProxyCallToHubRequest struct {
 }
 
 func (d *HubProxyController) ProxyCallToHub(r *http.Request, args *ProxyCallToHubRequest, reply *struct{ Data interface{} }) error {
-	response, err := d.hubProxy.ProxyCallToHub(args.Call, args.Args)
+	response, err := d.hubProxy.ProxyCallToHub(r.Context(), args.Call, args.Args)
 	if err != nil {
-		log.Printf("Call error: %v", err)
+		logging.Error(r.Context(), "Call error", "error", err)
 		return err
 	}
 	reply.Data = response
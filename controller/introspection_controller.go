@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+const (
+	hubAPICallListMethod = "api.getApiCallList"
+	//undefinedSignature is returned by system.methodSignature for the methods without a known signature
+	undefinedSignature = "undef"
+)
+
+//MethodDescription documents a method for the introspection methods.
+//Every signature lists the type of the returned value followed by the types of the parameters.
+type MethodDescription struct {
+	Signatures [][]string
+	Help       string
+}
+
+//MethodDescriber provides the methods served by the gateway itself and their descriptions
+type MethodDescriber interface {
+	ListMethods() []string
+	DescribeMethod(method string) (MethodDescription, bool)
+}
+
+type IntrospectionController struct {
+	methodDescriber MethodDescriber
+	hubProxy        gateway.HubProxy
+}
+
+func NewIntrospectionController(methodDescriber MethodDescriber, hubProxy gateway.HubProxy) *IntrospectionController {
+	return &IntrospectionController{methodDescriber, hubProxy}
+}
+
+type ListMethodsRequest struct {
+	HubSessionKey string
+}
+
+type MethodIntrospectionRequest struct {
+	MethodName string
+}
+
+//ListMethods returns the methods served by the gateway itself. If a hubSessionKey is passed, it also returns
+//the methods of the Hub API, which can be called on the peripheral servers via the unicast and multicast namespaces.
+func (h *IntrospectionController) ListMethods(r *http.Request, args *ListMethodsRequest, reply *struct{ Data []string }) error {
+	methods := h.methodDescriber.ListMethods()
+	if args.HubSessionKey != "" {
+		callList, err := h.hubProxy.ProxyCallToHub(r.Context(), hubAPICallListMethod, []interface{}{args.HubSessionKey})
+		if err != nil {
+			logging.Error(r.Context(), "Call error", "error", err)
+			return err
+		}
+		for _, method := range hubAPIMethods(callList) {
+			methods = append(methods, "unicast."+method, "multicast."+method)
+		}
+		sort.Strings(methods)
+	}
+	reply.Data = methods
+	return nil
+}
+
+func (h *IntrospectionController) MethodSignature(r *http.Request, args *MethodIntrospectionRequest, reply *struct{ Data interface{} }) error {
+	description, ok := h.methodDescriber.DescribeMethod(args.MethodName)
+	if !ok || len(description.Signatures) == 0 {
+		reply.Data = undefinedSignature
+		return nil
+	}
+	reply.Data = description.Signatures
+	return nil
+}
+
+func (h *IntrospectionController) MethodHelp(r *http.Request, args *MethodIntrospectionRequest, reply *struct{ Data string }) error {
+	description, _ := h.methodDescriber.DescribeMethod(args.MethodName)
+	reply.Data = description.Help
+	return nil
+}
+
+//hubAPIMethods returns the full names of the methods in the output of api.getApiCallList,
+//which maps every namespace to the calls it contains
+func hubAPIMethods(callList interface{}) []string {
+	namespaces, _ := callList.(map[string]interface{})
+	methods := make(map[string]bool)
+	for namespace, calls := range namespaces {
+		callsByKey, _ := calls.(map[string]interface{})
+		for key, call := range callsByKey {
+			name := strings.SplitN(key, "(", 2)[0]
+			if callInfo, ok := call.(map[string]interface{}); ok {
+				if callName, ok := callInfo["name"].(string); ok {
+					name = callName
+				}
+			}
+			methods[namespace+"."+name] = true
+		}
+	}
+	result := make([]string, 0, len(methods))
+	for method := range methods {
+		result = append(result, method)
+	}
+	sort.Strings(result)
+	return result
+}
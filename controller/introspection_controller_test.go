@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type mockMethodDescriber struct{}
+
+func (m *mockMethodDescriber) ListMethods() []string {
+	return []string{"hub.login", "system.listMethods"}
+}
+
+func (m *mockMethodDescriber) DescribeMethod(method string) (MethodDescription, bool) {
+	if method == "hub.login" {
+		return MethodDescription{[][]string{{"string", "string", "string"}}, "Logs in to the Hub"}, true
+	}
+	return MethodDescription{}, false
+}
+
+type mockHubProxy struct {
+	mockProxyCallToHub func(ctx context.Context, call string, args []interface{}) (interface{}, error)
+}
+
+func (m *mockHubProxy) ProxyCallToHub(ctx context.Context, call string, args []interface{}) (interface{}, error) {
+	return m.mockProxyCallToHub(ctx, call, args)
+}
+
+func Test_ListMethods(t *testing.T) {
+	tt := []struct {
+		name               string
+		hubSessionKey      string
+		mockProxyCallToHub func(ctx context.Context, call string, args []interface{}) (interface{}, error)
+		expectedMethods    []string
+		expectedErr        string
+	}{
+		{
+			name:            "ListMethods hub_methods_only",
+			expectedMethods: []string{"hub.login", "system.listMethods"},
+		},
+		{
+			name:          "ListMethods with_hub_api_methods",
+			hubSessionKey: "hubSessionKey",
+			mockProxyCallToHub: func(ctx context.Context, call string, args []interface{}) (interface{}, error) {
+				if call != "api.getApiCallList" || args[0] != "hubSessionKey" {
+					return nil, errors.New("unexpected call")
+				}
+				return map[string]interface{}{
+					"system": map[string]interface{}{
+						"listSystems(sessionKey)": map[string]interface{}{"name": "listSystems", "parameters": []interface{}{"string"}},
+						"getId(sessionKey,name)":  map[string]interface{}{"name": "getId", "parameters": []interface{}{"string", "string"}},
+					},
+					"api": map[string]interface{}{
+						"getVersion()": map[string]interface{}{"name": "getVersion"},
+					},
+				}, nil
+			},
+			expectedMethods: []string{
+				"hub.login",
+				"multicast.api.getVersion", "multicast.system.getId", "multicast.system.listSystems",
+				"system.listMethods",
+				"unicast.api.getVersion", "unicast.system.getId", "unicast.system.listSystems",
+			},
+		},
+		{
+			name:          "ListMethods hub_call_failed",
+			hubSessionKey: "hubSessionKey",
+			mockProxyCallToHub: func(ctx context.Context, call string, args []interface{}) (interface{}, error) {
+				return nil, errors.New("call_error")
+			},
+			expectedErr: "call_error",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			introspectionController := NewIntrospectionController(new(mockMethodDescriber), &mockHubProxy{tc.mockProxyCallToHub})
+			reply := &struct{ Data []string }{}
+
+			err := introspectionController.ListMethods(httptest.NewRequest("POST", "/hub/rpc/api", nil), &ListMethodsRequest{tc.hubSessionKey}, reply)
+
+			if err != nil && tc.expectedErr != err.Error() {
+				t.Fatalf("expected and actual errors don't match. Actual was:  %v. Expected was: %v", err, tc.expectedErr)
+			}
+			if err == nil && !reflect.DeepEqual(reply.Data, tc.expectedMethods) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", reply.Data, tc.expectedMethods)
+			}
+		})
+	}
+}
+
+func Test_MethodSignature_and_MethodHelp(t *testing.T) {
+	introspectionController := NewIntrospectionController(new(mockMethodDescriber), nil)
+	request := httptest.NewRequest("POST", "/hub/rpc/api", nil)
+
+	signatureReply := &struct{ Data interface{} }{}
+	introspectionController.MethodSignature(request, &MethodIntrospectionRequest{"hub.login"}, signatureReply)
+	if !reflect.DeepEqual(signatureReply.Data, [][]string{{"string", "string", "string"}}) {
+		t.Fatalf("unexpected signature: %v", signatureReply.Data)
+	}
+	introspectionController.MethodSignature(request, &MethodIntrospectionRequest{"unicast.system.listSystems"}, signatureReply)
+	if signatureReply.Data != "undef" {
+		t.Fatalf("unexpected signature: %v", signatureReply.Data)
+	}
+
+	helpReply := &struct{ Data string }{}
+	introspectionController.MethodHelp(request, &MethodIntrospectionRequest{"hub.login"}, helpReply)
+	if helpReply.Data != "Logs in to the Hub" {
+		t.Fatalf("unexpected help: %v", helpReply.Data)
+	}
+}
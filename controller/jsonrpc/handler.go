@@ -0,0 +1,166 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+const contentType = "application/json"
+
+//callState holds the information of a call which is needed to answer it
+//when the RPC server rejects it without passing the error to the Codec
+type callState struct {
+	id           json.RawMessage
+	notification bool
+	err          error
+}
+
+type callStateKey struct{}
+
+func callStateFromContext(ctx context.Context) *callState {
+	state, _ := ctx.Value(callStateKey{}).(*callState)
+	return state
+}
+
+//NewHandler wraps the RPC server serving the JSON-RPC Codec. It splits the batch requests into single calls,
+//which are served concurrently, and answers with JSON-RPC errors the calls rejected by the RPC server.
+//Requests with other content types are passed through.
+func NewHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if r.Method != "POST" || mediaType != contentType {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeResponse(w, mustEncodeError(nullID, controller.FaultDecode))
+			return
+		}
+		body = bytes.TrimSpace(body)
+
+		if len(body) == 0 || body[0] != '[' {
+			response := serveCall(handler, r, body)
+			if response == nil {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			writeResponse(w, response)
+			return
+		}
+
+		var calls []json.RawMessage
+		if err := json.Unmarshal(body, &calls); err != nil {
+			writeResponse(w, mustEncodeError(nullID, controller.FaultDecode))
+			return
+		}
+		if len(calls) == 0 {
+			writeResponse(w, mustEncodeError(nullID, controller.FaultInvalidRequest))
+			return
+		}
+		responses := serveBatch(handler, r, calls)
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeResponse(w, append(append([]byte("["), bytes.Join(responses, []byte(","))...), ']'))
+	})
+}
+
+//serveBatch serves the calls of a batch concurrently, returning the responses in the order of the calls
+func serveBatch(handler http.Handler, r *http.Request, calls []json.RawMessage) [][]byte {
+	responses := make([][]byte, len(calls))
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func(i int, call json.RawMessage) {
+			defer wg.Done()
+			responses[i] = serveCall(handler, r, call)
+		}(i, call)
+	}
+	wg.Wait()
+
+	nonEmptyResponses := make([][]byte, 0, len(responses))
+	for _, response := range responses {
+		if response != nil {
+			nonEmptyResponses = append(nonEmptyResponses, response)
+		}
+	}
+	return nonEmptyResponses
+}
+
+//serveCall serves a single call, returning its encoded response or nil for notifications.
+//A panic while serving the call is answered with an internal error, as the batch calls run on their own goroutines.
+func serveCall(handler http.Handler, r *http.Request, call []byte) (response []byte) {
+	state := &callState{id: nullID}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logging.Error(r.Context(), "Error ocurred while serving the call", "error", recovered)
+			response = nil
+			if !state.notification {
+				response = mustEncodeError(state.id, controller.FaultInternalError)
+			}
+		}
+	}()
+	callRequest := r.Clone(context.WithValue(r.Context(), callStateKey{}, state))
+	callRequest.Body = ioutil.NopCloser(bytes.NewReader(call))
+	callRequest.ContentLength = int64(len(call))
+	recorder := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+
+	handler.ServeHTTP(recorder, callRequest)
+
+	if state.notification {
+		return nil
+	}
+	if recorder.status == http.StatusOK {
+		return bytes.TrimSpace(recorder.body.Bytes())
+	}
+	err := state.err
+	if err == nil {
+		fault := controller.FaultMethodNotFound
+		fault.Message += ": " + strings.TrimPrefix(recorder.body.String(), "rpc: ")
+		err = fault
+	}
+	return mustEncodeError(state.id, err)
+}
+
+//responseRecorder buffers the response of a single call
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func mustEncodeError(id json.RawMessage, err error) []byte {
+	response, encodeErr := encodeError(id, err)
+	if encodeErr != nil {
+		response, _ = encodeError(nullID, controller.FaultInternalError)
+	}
+	return response
+}
+
+func writeResponse(w http.ResponseWriter, response []byte) {
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Write(response)
+}
@@ -0,0 +1,227 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/gorilla/rpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/metrics"
+)
+
+// implements a Gorilla JSON-RPC 2.0 Codec, see https://www.jsonrpc.org/specification
+
+const version = "2.0"
+
+//MethodResolver provides the mappings of the requested methods to the service methods and their parsers
+type MethodResolver interface {
+	ResolveMethod(requestMethod string) (serviceMethod, namespace string, parser xmlrpc.Parser)
+	ResolveMethodLabel(requestMethod string) string
+}
+
+type Codec struct {
+	methodResolver MethodResolver
+}
+
+//NewCodec instantiates a Codec serving the same methods as the XMLRPC codec resolving them.
+//It must be used behind the Handler, which takes care of batches and of the requests rejected before reaching the service.
+func NewCodec(methodResolver MethodResolver) *Codec {
+	return &Codec{methodResolver}
+}
+
+type serverRequest struct {
+	method       string
+	params       []interface{}
+	id           json.RawMessage
+	notification bool
+}
+
+type serverResponse struct {
+	Version string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type serverErrorResponse struct {
+	Version string          `json:"jsonrpc"`
+	Error   serverError     `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type serverError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+var nullID = json.RawMessage("null")
+
+func (c *Codec) NewRequest(r *http.Request) rpc.CodecRequest {
+	state := callStateFromContext(r.Context())
+	if state == nil {
+		state = &callState{id: nullID}
+	}
+	request, err := decodeRequest(r.Body)
+	if request != nil {
+		state.id, state.notification = request.id, request.notification
+	}
+	if err != nil {
+		state.err = err
+		return &CodecRequest{err: err, state: state, ctx: r.Context()}
+	}
+
+	serviceMethod, namespace, parser := c.methodResolver.ResolveMethod(request.method)
+	metrics.SetRequestMethod(r.Context(), namespace, c.methodResolver.ResolveMethodLabel(request.method))
+
+	return &CodecRequest{
+		request:       &xmlrpc.ServerRequest{MethodName: request.method, Params: request.params},
+		serviceMethod: serviceMethod,
+		parser:        parser,
+		state:         state,
+		ctx:           r.Context(),
+	}
+}
+
+func decodeRequest(body io.Reader) (*serverRequest, error) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&fields); err != nil {
+		if _, ok := err.(*json.UnmarshalTypeError); ok {
+			return nil, controller.FaultInvalidRequest
+		}
+		return nil, controller.FaultDecode
+	}
+
+	request := &serverRequest{id: nullID}
+	id, hasID := fields["id"]
+	if hasID {
+		request.id = id
+	}
+
+	var requestVersion string
+	if json.Unmarshal(fields["jsonrpc"], &requestVersion) != nil || requestVersion != version {
+		return request, controller.FaultInvalidRequest
+	}
+	if json.Unmarshal(fields["method"], &request.method) != nil || request.method == "" {
+		return request, controller.FaultInvalidRequest
+	}
+	//the invalid requests are answered even without id, as they cannot be told apart from invalid notifications
+	request.notification = !hasID
+
+	if rawParams, ok := fields["params"]; ok && !bytes.Equal(bytes.TrimSpace(rawParams), nullID) {
+		//parameters by name are not supported, as all the methods take positional ones
+		decoder := json.NewDecoder(bytes.NewReader(rawParams))
+		decoder.UseNumber()
+		if err := decoder.Decode(&request.params); err != nil {
+			return request, controller.FaultInvalidParams
+		}
+		for i, param := range request.params {
+			value, ok := toXMLRPCValue(param)
+			if !ok {
+				return request, controller.FaultInvalidParams
+			}
+			request.params[i] = value
+		}
+	}
+	return request, nil
+}
+
+//toXMLRPCValue converts the numbers to the types returned by the XMLRPC decoder, which the parsers expect.
+//It returns false for null values, which have no XMLRPC counterpart.
+func toXMLRPCValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case json.Number:
+		if integer, err := v.Int64(); err == nil {
+			return integer, true
+		}
+		float, _ := v.Float64()
+		return float, true
+	case []interface{}:
+		for i, item := range v {
+			converted, ok := toXMLRPCValue(item)
+			if !ok {
+				return nil, false
+			}
+			v[i] = converted
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			converted, ok := toXMLRPCValue(item)
+			if !ok {
+				return nil, false
+			}
+			v[key] = converted
+		}
+	}
+	return value, true
+}
+
+type CodecRequest struct {
+	serviceMethod string
+	request       *xmlrpc.ServerRequest
+	parser        xmlrpc.Parser
+	err           error
+	state         *callState
+	ctx           context.Context
+}
+
+func (c *CodecRequest) Method() (string, error) {
+	if c.err == nil {
+		return c.serviceMethod, nil
+	}
+	return "", c.err
+}
+
+func (c *CodecRequest) ReadRequest(args interface{}) error {
+	if c.parser == nil {
+		c.state.err = controller.FaultMethodNotFound
+		return c.state.err
+	}
+	c.err = c.parser(c.ctx, c.request, args)
+	if c.err != nil {
+		c.state.err = c.err
+		return c.err
+	}
+	return nil
+}
+
+func (c *CodecRequest) WriteResponse(w http.ResponseWriter, response interface{}, methodErr error) error {
+	if methodErr != nil {
+		metrics.SetRequestFault(c.ctx)
+	}
+	if c.state.notification {
+		return nil
+	}
+	var encodedResponse []byte
+	var err error
+	if methodErr != nil {
+		encodedResponse, err = encodeError(c.state.id, methodErr)
+	} else {
+		encodedResponse, err = json.Marshal(&serverResponse{version, resultOf(response), c.state.id})
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(encodedResponse)
+	return nil
+}
+
+//resultOf returns the Data field of the reply of the service methods
+func resultOf(reply interface{}) interface{} {
+	val := reflect.ValueOf(reply).Elem()
+	if val.Kind() != reflect.Struct || val.NumField() == 0 {
+		return nil
+	}
+	return val.Field(0).Interface()
+}
+
+func encodeError(id json.RawMessage, err error) ([]byte, error) {
+	fault := controller.ToFaultError(err)
+	return json.Marshal(&serverErrorResponse{version, serverError{fault.Code, fault.Message}, id})
+}
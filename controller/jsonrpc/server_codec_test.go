@@ -0,0 +1,222 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/rpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+)
+
+type EchoService struct{}
+
+type EchoRequest struct {
+	HubSessionKey string
+	ServerIDs     []int64
+	Args          []interface{}
+}
+
+func (s *EchoService) Echo(r *http.Request, args *EchoRequest, reply *struct{ Data *EchoRequest }) error {
+	reply.Data = args
+	return nil
+}
+
+func (s *EchoService) Fail(r *http.Request, args *EchoRequest, reply *struct{ Data string }) error {
+	if args.HubSessionKey == "fault" {
+		return controller.FaultInvalidCredentials
+	}
+	return errors.New("call_error")
+}
+
+func (s *EchoService) Panic(r *http.Request, args *EchoRequest, reply *struct{ Data string }) error {
+	panic("call_panic")
+}
+
+func echoRequestParser(ctx context.Context, request *xmlrpc.ServerRequest, output interface{}) error {
+	if len(request.Params) < 1 {
+		return controller.FaultWrongArgumentsNumber
+	}
+	hubSessionKey, ok := request.Params[0].(string)
+	if !ok {
+		return controller.FaultInvalidParams
+	}
+	args := output.(*EchoRequest)
+	args.HubSessionKey = hubSessionKey
+	if len(request.Params) > 1 {
+		serverIDs, ok := request.Params[1].([]interface{})
+		if !ok {
+			return controller.FaultInvalidParams
+		}
+		for _, serverID := range serverIDs {
+			args.ServerIDs = append(args.ServerIDs, serverID.(int64))
+		}
+		args.Args = request.Params[2:]
+	}
+	return nil
+}
+
+func newTestHandler() http.Handler {
+	xmlrpcCodec := xmlrpc.NewCodec()
+	xmlrpcCodec.RegisterMapping("test.echo", "EchoService.Echo", echoRequestParser)
+	xmlrpcCodec.RegisterMapping("test.fail", "EchoService.Fail", echoRequestParser)
+	xmlrpcCodec.RegisterMapping("test.panic", "EchoService.Panic", echoRequestParser)
+
+	rpcServer := rpc.NewServer()
+	rpcServer.RegisterCodec(xmlrpcCodec, "text/xml")
+	rpcServer.RegisterCodec(NewCodec(xmlrpcCodec), "application/json")
+	rpcServer.RegisterService(new(EchoService), "")
+	return NewHandler(rpcServer)
+}
+
+func Test_ServeJSONRPC(t *testing.T) {
+	tt := []struct {
+		name             string
+		body             string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:             "ServeJSONRPC success",
+			body:             `{"jsonrpc":"2.0","method":"test.echo","params":["sessionKey",[1000010000,1000010001],1.5,{"key":2}],"id":1}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"jsonrpc":"2.0","result":{"HubSessionKey":"sessionKey","ServerIDs":[1000010000,1000010001],"Args":[1.5,{"key":2}]},"id":1}`,
+		},
+		{
+			name:             "ServeJSONRPC fault_error",
+			body:             `{"jsonrpc":"2.0","method":"test.fail","params":["fault"],"id":"id"}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"jsonrpc":"2.0","error":{"code":2950,"message":"Either the password or username is incorrect"},"id":"id"}`,
+		},
+		{
+			name:             "ServeJSONRPC application_error",
+			body:             `{"jsonrpc":"2.0","method":"test.fail","params":["sessionKey"],"id":1}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"jsonrpc":"2.0","error":{"code":-32500,"message":"Application Error: call_error"},"id":1}`,
+		},
+		{
+			name:             "ServeJSONRPC invalid_params",
+			body:             `{"jsonrpc":"2.0","method":"test.echo","params":[1],"id":1}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid Method Parameters"},"id":1}`,
+		},
+		{
+			name:             "ServeJSONRPC params_by_name",
+			body:             `{"jsonrpc":"2.0","method":"test.echo","params":{"hubSessionKey":"sessionKey"},"id":1}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid Method Parameters"},"id":1}`,
+		},
+		{
+			name:             "ServeJSONRPC method_not_found",
+			body:             `{"jsonrpc":"2.0","method":"test.unknown","params":[],"id":1}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found: can't find service \"test.unknown\""},"id":1}`,
+		},
+		{
+			name:             "ServeJSONRPC invalid_request",
+			body:             `{"jsonrpc":"1.0","method":"test.echo","params":[],"id":1}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":1}`,
+		},
+		{
+			name:             "ServeJSONRPC parse_error",
+			body:             `{"jsonrpc":"2.0","method"`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parsing error: not well formed"},"id":null}`,
+		},
+		{
+			name:           "ServeJSONRPC notification",
+			body:           `{"jsonrpc":"2.0","method":"test.fail","params":["sessionKey"]}`,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "ServeJSONRPC batch",
+			body: `[{"jsonrpc":"2.0","method":"test.echo","params":["sessionKey"],"id":1},
+				{"jsonrpc":"2.0","method":"test.echo","params":["sessionKey"]},
+				1,
+				{"jsonrpc":"2.0","method":"test.fail","params":["fault"],"id":2}]`,
+			expectedStatus: http.StatusOK,
+			expectedResponse: `[{"jsonrpc":"2.0","result":{"HubSessionKey":"sessionKey","ServerIDs":null,"Args":null},"id":1},` +
+				`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null},` +
+				`{"jsonrpc":"2.0","error":{"code":2950,"message":"Either the password or username is incorrect"},"id":2}]`,
+		},
+		{
+			name:             "ServeJSONRPC empty_batch",
+			body:             `[]`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`,
+		},
+		{
+			name: "ServeJSONRPC batch_with_null_params",
+			body: `[{"jsonrpc":"2.0","method":"test.echo","params":[null],"id":1},
+				{"jsonrpc":"2.0","method":"test.echo","params":["sessionKey",[null]],"id":2},
+				{"jsonrpc":"2.0","method":"test.echo","params":["sessionKey"],"id":3}]`,
+			expectedStatus: http.StatusOK,
+			expectedResponse: `[{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid Method Parameters"},"id":1},` +
+				`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid Method Parameters"},"id":2},` +
+				`{"jsonrpc":"2.0","result":{"HubSessionKey":"sessionKey","ServerIDs":null,"Args":null},"id":3}]`,
+		},
+		{
+			name: "ServeJSONRPC batch_with_panic",
+			body: `[{"jsonrpc":"2.0","method":"test.panic","params":["sessionKey"],"id":1},
+				{"jsonrpc":"2.0","method":"test.panic","params":["sessionKey"]},
+				{"jsonrpc":"2.0","method":"test.echo","params":["sessionKey"],"id":2}]`,
+			expectedStatus: http.StatusOK,
+			expectedResponse: `[{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal Server Error"},"id":1},` +
+				`{"jsonrpc":"2.0","result":{"HubSessionKey":"sessionKey","ServerIDs":null,"Args":null},"id":2}]`,
+		},
+		{
+			name:           "ServeJSONRPC batch_of_notifications",
+			body:           `[{"jsonrpc":"2.0","method":"test.echo","params":["sessionKey"]}]`,
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	handler := newTestHandler()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/hub/rpc/api", strings.NewReader(tc.body))
+			request.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", recorder.Code, tc.expectedStatus)
+			}
+			if tc.expectedResponse == "" {
+				if recorder.Body.Len() != 0 {
+					t.Fatalf("unexpected response: %v", recorder.Body.String())
+				}
+				return
+			}
+			var response, expectedResponse interface{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("invalid JSON response: %v", recorder.Body.String())
+			}
+			json.Unmarshal([]byte(tc.expectedResponse), &expectedResponse)
+			if !reflect.DeepEqual(response, expectedResponse) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", recorder.Body.String(), tc.expectedResponse)
+			}
+		})
+	}
+}
+
+func Test_ServeXMLRPC_passed_through(t *testing.T) {
+	body := `<?xml version="1.0"?><methodCall><methodName>test.echo</methodName><params><param><value><string>sessionKey</string></value></param></params></methodCall>`
+	request := httptest.NewRequest("POST", "/hub/rpc/api", strings.NewReader(body))
+	request.Header.Set("Content-Type", "text/xml")
+	recorder := httptest.NewRecorder()
+
+	newTestHandler().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "<string>sessionKey</string>") {
+		t.Fatalf("unexpected XMLRPC response: %v %v", recorder.Code, recorder.Body.String())
+	}
+}
@@ -1,10 +1,10 @@
 package controller
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
 type HubLoginController struct {
@@ -22,9 +22,9 @@ func NewHubLoginController(hubLoginer gateway.HubLoginer, responseTransformer mu
 }
 
 func (h *HubLoginController) Login(r *http.Request, args *LoginRequest, reply *struct{ Data string }) error {
-	hubSessionKey, err := h.hubLoginer.Login(args.Username, args.Password)
+	hubSessionKey, err := h.hubLoginer.Login(r.Context(), args.Username, args.Password)
 	if err != nil {
-		log.Printf("Login error: %v", err)
+		logging.Error(r.Context(), "Login error", "error", err)
 		return err
 	}
 	reply.Data = hubSessionKey
@@ -32,29 +32,35 @@ func (h *HubLoginController) Login(r *http.Request, args *LoginRequest, reply *s
 }
 
 func (h *HubLoginController) LoginWithAuthRelayMode(r *http.Request, args *LoginRequest, reply *struct{ Data string }) error {
-	hubSessionKey, err := h.hubLoginer.LoginWithAuthRelayMode(args.Username, args.Password)
+	hubSessionKey, err := h.hubLoginer.LoginWithAuthRelayMode(r.Context(), args.Username, args.Password)
 	if err != nil {
-		log.Printf("Login error: %v", err)
+		logging.Error(r.Context(), "Login error", "error", err)
 		return err
 	}
 	reply.Data = hubSessionKey
 	return nil
 }
 
+type LoginWithAutoconnectModeRequest struct {
+	Username string
+	Password string
+	Options  ServerAuthenticationOptions
+}
+
 type LoginWithAutoconnectModeResponse struct {
 	SessionKey         string
 	Successful, Failed MulticastStateResponse
 }
 
-func (h *HubLoginController) LoginWithAutoconnectMode(r *http.Request, args *LoginRequest, reply *struct {
+func (h *HubLoginController) LoginWithAutoconnectMode(r *http.Request, args *LoginWithAutoconnectModeRequest, reply *struct {
 	Data *LoginWithAutoconnectModeResponse
 }) error {
-	loginResponse, err := h.hubLoginer.LoginWithAutoconnectMode(args.Username, args.Password)
+	loginResponse, err := h.hubLoginer.LoginWithAutoconnectMode(r.Context(), args.Username, args.Password)
 	if err != nil {
-		log.Printf("Login error: %v", err)
+		logging.Error(r.Context(), "Login error", "error", err)
 		return err
 	}
-	attachToServersResponse := h.responseTransformer(loginResponse.AttachToServersResponse)
+	attachToServersResponse := h.responseTransformer(loginResponse.AttachToServersResponse, args.Options.LegacyFailedResponses)
 	reply.Data = &LoginWithAutoconnectModeResponse{loginResponse.HubSessionKey, attachToServersResponse.Successful, attachToServersResponse.Failed}
 	return nil
 }
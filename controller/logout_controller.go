@@ -1,10 +1,10 @@
 package controller
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
 type HubLogoutController struct {
@@ -20,9 +20,9 @@ type LogoutRequest struct {
 }
 
 func (h *HubLogoutController) Logout(r *http.Request, args *LogoutRequest, reply *struct{ Data string }) error {
-	err := h.hubLogouter.Logout(args.HubSessionKey)
+	err := h.hubLogouter.Logout(r.Context(), args.HubSessionKey)
 	if err != nil {
-		log.Printf("Logout error: %v", err)
+		logging.Error(r.Context(), "Logout error", "error", err)
 		return err
 	}
 	return nil
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"net/http"
+)
+
+const multicallMethod = "system.multicall"
+
+//CallDispatcher executes a call through the same method mappings and services as if it was received in its own request
+type CallDispatcher func(r *http.Request, methodName string, params []interface{}) (interface{}, error)
+
+type MulticallController struct {
+	dispatchCall CallDispatcher
+}
+
+func NewMulticallController(dispatchCall CallDispatcher) *MulticallController {
+	return &MulticallController{dispatchCall}
+}
+
+type MulticallRequest struct {
+	Calls []MulticallCall
+}
+
+type MulticallCall struct {
+	MethodName string
+	Params     []interface{}
+}
+
+//Multicall executes the calls in order. The result of every successful call is returned wrapped in a one-element array
+//and the fault of every failed call is returned as is, in the format of the standard system.multicall method.
+func (h *MulticallController) Multicall(r *http.Request, args *MulticallRequest, reply *struct{ Data []interface{} }) error {
+	results := make([]interface{}, 0, len(args.Calls))
+	for _, call := range args.Calls {
+		if call.MethodName == multicallMethod {
+			fault := FaultInvalidRequest
+			fault.Message += ": recursive " + multicallMethod + " calls are not allowed"
+			results = append(results, fault)
+			continue
+		}
+		result, err := h.dispatchCall(r, call.MethodName, call.Params)
+		if err != nil {
+			results = append(results, ToFaultError(err))
+			continue
+		}
+		results = append(results, []interface{}{result})
+	}
+	reply.Data = results
+	return nil
+}
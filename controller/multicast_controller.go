@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
 )
@@ -10,7 +11,7 @@ type MulticastController struct {
 	multicaster         gateway.Multicaster
 	responseTransformer multicastResponseTransformer
 }
-type multicastResponseTransformer func(multicastResponse *gateway.MulticastResponse) *MulticastResponse
+type multicastResponseTransformer func(multicastResponse *gateway.MulticastResponse, legacyFailedResponses bool) *MulticastResponse
 
 type MulticastResponse struct {
 	Successful, Failed MulticastStateResponse
@@ -19,6 +20,23 @@ type MulticastResponse struct {
 type MulticastStateResponse struct {
 	ServerIds []int64
 	Responses []interface{}
+	Attempts  []int
+}
+
+//KeyedMulticastResponse holds the responses of a multicast call in structs keyed by server ID
+type KeyedMulticastResponse struct {
+	Successful, Failed map[string]interface{}
+}
+
+//FailedResponse describes why the call to a server failed. Unless the caller asks for the legacy format,
+//it is returned in the Responses of the Failed section in place of the bare error message
+type FailedResponse struct {
+	FaultCode     int
+	FaultString   string
+	ErrorCategory string
+	Endpoint      string
+	Attempts      int
+	ElapsedMillis int64
 }
 
 func NewMulticastController(multicaster gateway.Multicaster, responseTransformer multicastResponseTransformer) *MulticastController {
@@ -30,13 +48,43 @@ type MulticastRequest struct {
 	HubSessionKey string
 	ServerIDs     []int64
 	ArgsByServer  map[int64][]interface{}
+	Options       MulticastOptions
 }
 
-func (h *MulticastController) Multicast(r *http.Request, args *MulticastRequest, reply *struct{ Data *MulticastResponse }) error {
-	multicastResponse, err := h.multicaster.Multicast(args.HubSessionKey, args.Call, args.ServerIDs, args.ArgsByServer)
+//MulticastOptions are optionally passed by the caller as a struct after all the per-server arguments
+type MulticastOptions struct {
+	MaxConcurrency        int
+	Timeout               int
+	LegacyFailedResponses bool
+	KeyedResults          bool
+}
+
+func (h *MulticastController) Multicast(r *http.Request, args *MulticastRequest, reply *struct{ Data interface{} }) error {
+	ctx, cancel, err := callContext(r, args.Options.Timeout)
 	if err != nil {
 		return err
 	}
-	reply.Data = h.responseTransformer(multicastResponse)
+	defer cancel()
+	multicastResponse, err := h.multicaster.Multicast(ctx, args.HubSessionKey, args.Call, args.ServerIDs, args.ArgsByServer, args.Options.MaxConcurrency)
+	if err != nil {
+		return err
+	}
+	reply.Data = formatMulticastResponse(h.responseTransformer(multicastResponse, args.Options.LegacyFailedResponses), args.Options)
 	return nil
 }
+
+//formatMulticastResponse returns the response in the shape requested by the caller
+func formatMulticastResponse(multicastResponse *MulticastResponse, options MulticastOptions) interface{} {
+	if options.KeyedResults {
+		return &KeyedMulticastResponse{keyByServerID(multicastResponse.Successful), keyByServerID(multicastResponse.Failed)}
+	}
+	return multicastResponse
+}
+
+func keyByServerID(stateResponse MulticastStateResponse) map[string]interface{} {
+	responsesByServer := make(map[string]interface{}, len(stateResponse.ServerIds))
+	for i, serverID := range stateResponse.ServerIds {
+		responsesByServer[strconv.FormatInt(serverID, 10)] = stateResponse.Responses[i]
+	}
+	return responsesByServer
+}
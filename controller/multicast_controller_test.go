@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_formatMulticastResponse(t *testing.T) {
+	multicastResponse := &MulticastResponse{
+		MulticastStateResponse{[]int64{2, 1}, []interface{}{"response_2", "response_1"}, []int{1, 1}},
+		MulticastStateResponse{[]int64{3}, []interface{}{"call_error"}, []int{3}},
+	}
+
+	tt := []struct {
+		name             string
+		options          MulticastOptions
+		expectedResponse interface{}
+	}{
+		{name: "formatMulticastResponse list", expectedResponse: multicastResponse},
+		{
+			name:    "formatMulticastResponse keyed_results",
+			options: MulticastOptions{KeyedResults: true},
+			expectedResponse: &KeyedMulticastResponse{
+				map[string]interface{}{"1": "response_1", "2": "response_2"},
+				map[string]interface{}{"3": "call_error"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			response := formatMulticastResponse(multicastResponse, tc.options)
+			if !reflect.DeepEqual(response, tc.expectedResponse) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", response, tc.expectedResponse)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+type MulticastJobController struct {
+	asyncMulticaster    gateway.AsyncMulticaster
+	responseTransformer multicastResponseTransformer
+}
+
+func NewMulticastJobController(asyncMulticaster gateway.AsyncMulticaster, responseTransformer multicastResponseTransformer) *MulticastJobController {
+	return &MulticastJobController{asyncMulticaster, responseTransformer}
+}
+
+type MulticastJobRequest struct {
+	HubSessionKey string
+	JobID         string
+	Options       MulticastOptions
+}
+
+func (h *MulticastJobController) SubmitMulticastJob(r *http.Request, args *MulticastRequest, reply *struct{ Data string }) error {
+	jobID, err := h.asyncMulticaster.SubmitMulticastJob(r.Context(), args.HubSessionKey, args.Call, args.ServerIDs, args.ArgsByServer, args.Options.MaxConcurrency, time.Duration(args.Options.Timeout)*time.Second)
+	if err != nil {
+		logging.Error(r.Context(), "Error ocurred while submitting multicast job", "error", err)
+		return err
+	}
+	reply.Data = jobID
+	return nil
+}
+
+func (h *MulticastJobController) GetJobStatus(r *http.Request, args *MulticastJobRequest, reply *struct{ Data *gateway.MulticastJobStatus }) error {
+	jobStatus, err := h.asyncMulticaster.GetJobStatus(r.Context(), args.HubSessionKey, args.JobID)
+	if err != nil {
+		logging.Error(r.Context(), "Error ocurred while retrieving job status", "error", err)
+		return err
+	}
+	reply.Data = jobStatus
+	return nil
+}
+
+func (h *MulticastJobController) GetJobResult(r *http.Request, args *MulticastJobRequest, reply *struct{ Data interface{} }) error {
+	multicastResponse, err := h.asyncMulticaster.GetJobResult(r.Context(), args.HubSessionKey, args.JobID)
+	if err != nil {
+		logging.Error(r.Context(), "Error ocurred while retrieving job result", "error", err)
+		return err
+	}
+	reply.Data = formatMulticastResponse(h.responseTransformer(multicastResponse, args.Options.LegacyFailedResponses), args.Options)
+	return nil
+}
+
+func (h *MulticastJobController) CancelJob(r *http.Request, args *MulticastJobRequest, reply *struct{ Data string }) error {
+	err := h.asyncMulticaster.CancelJob(r.Context(), args.HubSessionKey, args.JobID)
+	if err != nil {
+		logging.Error(r.Context(), "Error ocurred while cancelling job", "error", err)
+		return err
+	}
+	return nil
+}
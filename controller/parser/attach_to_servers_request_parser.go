@@ -1,62 +1,91 @@
 package parser
 
 import (
-	"log"
+	"context"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/controller"
 	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
-func AttachToServersRequestParser(request *xmlrpc.ServerRequest, output interface{}) error {
+func AttachToServersRequestParser(ctx context.Context, request *xmlrpc.ServerRequest, output interface{}) error {
 	parsedRequest, ok := output.(*controller.AttachToServersRequest)
 	if !ok {
-		log.Printf("Error ocurred when parsing arguments")
+		logging.Error(ctx, "Error ocurred when parsing arguments")
 		return controller.FaultInvalidParams
 	}
 
 	args := request.Params
 	if len(args) < 2 {
-		log.Printf("Error ocurred when parsing arguments")
+		logging.Error(ctx, "Error ocurred when parsing arguments")
 		return controller.FaultWrongArgumentsNumber
 	}
 
 	hubSessionKey, ok := args[0].(string)
 	if !ok {
-		log.Printf("Error ocurred when parsing hubSessionKey argument")
+		logging.Error(ctx, "Error ocurred when parsing hubSessionKey argument")
 		return controller.FaultInvalidParams
 	}
 
-	serverIDs, err := resolveServerIDs(args[1])
+	serverIDs, err := resolveServerIDs(ctx, args[1])
 	if err != nil {
 		return err
 	}
 
+	var options controller.ServerAuthenticationOptions
+	if rawOptions, ok := args[len(args)-1].(map[string]interface{}); ok && len(args) > 2 {
+		options, err = resolveServerAuthenticationOptions(ctx, rawOptions)
+		if err != nil {
+			return err
+		}
+		args = args[:len(args)-1]
+	}
+
 	var credentialsByServer map[int64]*gateway.Credentials
 	if len(args) > 2 {
-		credentialsByServer, err = resolveCredentialsByServer(serverIDs, args[2:len(args)])
+		credentialsByServer, err = resolveCredentialsByServer(ctx, serverIDs, args[2:len(args)])
 		if err != nil {
 			return err
 		}
 	}
 
-	*parsedRequest = controller.AttachToServersRequest{hubSessionKey, serverIDs, credentialsByServer}
+	*parsedRequest = controller.AttachToServersRequest{hubSessionKey, serverIDs, credentialsByServer, options}
 	return nil
 }
 
-func resolveCredentialsByServer(serverIDs []int64, allServerArgs []interface{}) (map[int64]*gateway.Credentials, error) {
+func resolveServerAuthenticationOptions(ctx context.Context, rawOptions map[string]interface{}) (controller.ServerAuthenticationOptions, error) {
+	var options controller.ServerAuthenticationOptions
+	for name, value := range rawOptions {
+		switch name {
+		case "legacyFailedResponses":
+			legacyFailedResponses, ok := value.(bool)
+			if !ok {
+				logging.Error(ctx, "Error ocurred when parsing legacyFailedResponses option")
+				return options, controller.FaultInvalidParams
+			}
+			options.LegacyFailedResponses = legacyFailedResponses
+		default:
+			logging.Error(ctx, "Unknown server authentication option", "option", name)
+			return options, controller.FaultInvalidParams
+		}
+	}
+	return options, nil
+}
+
+func resolveCredentialsByServer(ctx context.Context, serverIDs []int64, allServerArgs []interface{}) (map[int64]*gateway.Credentials, error) {
 	if len(allServerArgs) != 2 {
-		log.Printf("Error ocurred when parsing credentials")
+		logging.Error(ctx, "Error ocurred when parsing credentials")
 		return nil, controller.FaultInvalidParams
 	}
 	usernames, ok := allServerArgs[0].([]interface{})
 	if !ok {
-		log.Printf("Error ocurred when parsing arguments")
+		logging.Error(ctx, "Error ocurred when parsing arguments")
 		return nil, controller.FaultInvalidParams
 	}
 	passwords, ok := allServerArgs[1].([]interface{})
 	if !ok {
-		log.Printf("Error ocurred when parsing arguments")
+		logging.Error(ctx, "Error ocurred when parsing arguments")
 		return nil, controller.FaultInvalidParams
 	}
 
@@ -64,12 +93,12 @@ func resolveCredentialsByServer(serverIDs []int64, allServerArgs []interface{})
 	for i, serverID := range serverIDs {
 		username, ok := usernames[i].(string)
 		if !ok {
-			log.Printf("Error ocurred when parsing arguments")
+			logging.Error(ctx, "Error ocurred when parsing arguments")
 			return nil, controller.FaultInvalidParams
 		}
 		password, ok := passwords[i].(string)
 		if !ok {
-			log.Printf("Error ocurred when parsing arguments")
+			logging.Error(ctx, "Error ocurred when parsing arguments")
 			return nil, controller.FaultInvalidParams
 		}
 		result[serverID] = &gateway.Credentials{username, password}
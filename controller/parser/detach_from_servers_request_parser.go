@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+func DetachFromServersRequestParser(ctx context.Context, request *xmlrpc.ServerRequest, output interface{}) error {
+	parsedRequest, ok := output.(*controller.DetachFromServersRequest)
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing arguments")
+		return controller.FaultInvalidParams
+	}
+
+	args := request.Params
+	if len(args) != 2 && len(args) != 3 {
+		logging.Error(ctx, "Error ocurred when parsing arguments")
+		return controller.FaultWrongArgumentsNumber
+	}
+
+	hubSessionKey, ok := args[0].(string)
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing hubSessionKey argument")
+		return controller.FaultInvalidParams
+	}
+
+	serverIDs, err := resolveServerIDs(ctx, args[1])
+	if err != nil {
+		return err
+	}
+
+	var options controller.ServerAuthenticationOptions
+	if len(args) == 3 {
+		rawOptions, ok := args[2].(map[string]interface{})
+		if !ok {
+			logging.Error(ctx, "Error ocurred when parsing options argument")
+			return controller.FaultInvalidParams
+		}
+		options, err = resolveServerAuthenticationOptions(ctx, rawOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	*parsedRequest = controller.DetachFromServersRequest{hubSessionKey, serverIDs, options}
+	return nil
+}
@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+func LoginWithAutoconnectModeRequestParser(ctx context.Context, request *xmlrpc.ServerRequest, output interface{}) error {
+	parsedRequest, ok := output.(*controller.LoginWithAutoconnectModeRequest)
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing arguments")
+		return controller.FaultInvalidParams
+	}
+
+	args := request.Params
+	if len(args) != 2 && len(args) != 3 {
+		logging.Error(ctx, "Error ocurred when parsing arguments")
+		return controller.FaultWrongArgumentsNumber
+	}
+
+	username, ok := args[0].(string)
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing username argument")
+		return controller.FaultInvalidParams
+	}
+	password, ok := args[1].(string)
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing password argument")
+		return controller.FaultInvalidParams
+	}
+
+	var options controller.ServerAuthenticationOptions
+	if len(args) == 3 {
+		rawOptions, ok := args[2].(map[string]interface{})
+		if !ok {
+			logging.Error(ctx, "Error ocurred when parsing options argument")
+			return controller.FaultInvalidParams
+		}
+		var err error
+		options, err = resolveServerAuthenticationOptions(ctx, rawOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	*parsedRequest = controller.LoginWithAutoconnectModeRequest{username, password, options}
+	return nil
+}
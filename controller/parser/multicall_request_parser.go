@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+func MulticallRequestParser(ctx context.Context, request *xmlrpc.ServerRequest, output interface{}) error {
+	parsedArgs, ok := output.(*controller.MulticallRequest)
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing arguments")
+		return controller.FaultInvalidParams
+	}
+
+	args := request.Params
+	if len(args) != 1 {
+		logging.Error(ctx, "Error ocurred when parsing arguments")
+		return controller.FaultWrongArgumentsNumber
+	}
+
+	calls, ok := args[0].([]interface{})
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing calls argument")
+		return controller.FaultInvalidParams
+	}
+
+	multicallCalls := make([]controller.MulticallCall, 0, len(calls))
+	for _, call := range calls {
+		callStruct, ok := call.(map[string]interface{})
+		if !ok {
+			logging.Error(ctx, "Error ocurred when parsing calls argument")
+			return controller.FaultInvalidParams
+		}
+		methodName, ok := callStruct["methodName"].(string)
+		if !ok {
+			logging.Error(ctx, "Error ocurred when parsing methodName of call")
+			return controller.FaultInvalidParams
+		}
+		params, ok := callStruct["params"].([]interface{})
+		if !ok && callStruct["params"] != nil {
+			logging.Error(ctx, "Error ocurred when parsing params of call", "method", methodName)
+			return controller.FaultInvalidParams
+		}
+		multicallCalls = append(multicallCalls, controller.MulticallCall{MethodName: methodName, Params: params})
+	}
+
+	*parsedArgs = controller.MulticallRequest{Calls: multicallCalls}
+	return nil
+}
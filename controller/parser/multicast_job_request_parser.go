@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+//MulticastJobRequestParser parses the hubSessionKey and the jobID, optionally followed by a struct with the multicast options
+func MulticastJobRequestParser(ctx context.Context, request *xmlrpc.ServerRequest, output interface{}) error {
+	parsedRequest, ok := output.(*controller.MulticastJobRequest)
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing arguments")
+		return controller.FaultInvalidParams
+	}
+
+	args := request.Params
+	if len(args) != 2 && len(args) != 3 {
+		logging.Error(ctx, "Error ocurred when parsing arguments")
+		return controller.FaultWrongArgumentsNumber
+	}
+
+	hubSessionKey, ok := args[0].(string)
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing hubSessionKey argument")
+		return controller.FaultInvalidParams
+	}
+
+	jobID, ok := args[1].(string)
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing jobID argument")
+		return controller.FaultInvalidParams
+	}
+
+	var options controller.MulticastOptions
+	if len(args) == 3 {
+		rawOptions, ok := args[2].(map[string]interface{})
+		if !ok {
+			logging.Error(ctx, "Error ocurred when parsing options argument")
+			return controller.FaultInvalidParams
+		}
+		var err error
+		options, err = resolveMulticastOptions(ctx, rawOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	*parsedRequest = controller.MulticastJobRequest{hubSessionKey, jobID, options}
+	return nil
+}
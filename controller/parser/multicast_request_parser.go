@@ -1,58 +1,68 @@
 package parser
 
 import (
-	"log"
+	"context"
 	"strings"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/controller"
 	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
-func MulticastRequestParser(request *xmlrpc.ServerRequest, output interface{}) error {
+func MulticastRequestParser(ctx context.Context, request *xmlrpc.ServerRequest, output interface{}) error {
 	parsedRequest, ok := output.(*controller.MulticastRequest)
 	if !ok {
-		log.Printf("Error ocurred when parsing arguments")
+		logging.Error(ctx, "Error ocurred when parsing arguments")
 		return controller.FaultInvalidParams
 	}
 
 	args := request.Params
 	if len(args) < 2 {
-		log.Printf("Error ocurred when parsing arguments")
+		logging.Error(ctx, "Error ocurred when parsing arguments")
 		return controller.FaultWrongArgumentsNumber
 	}
 
 	hubSessionKey, ok := args[0].(string)
 	if !ok {
-		log.Printf("Error ocurred when parsing hubSessionKey argument")
+		logging.Error(ctx, "Error ocurred when parsing hubSessionKey argument")
 		return controller.FaultInvalidParams
 	}
 
-	serverIDs, err := resolveServerIDs(args[1])
+	serverIDs, err := resolveServerIDs(ctx, args[1])
 	if err != nil {
 		return err
 	}
 
+	var options controller.MulticastOptions
+	if rawOptions, ok := args[len(args)-1].(map[string]interface{}); ok && len(args) > 2 {
+		options, err = resolveMulticastOptions(ctx, rawOptions)
+		if err != nil {
+			return err
+		}
+		args = args[:len(args)-1]
+	}
+
 	var argsByServer map[int64][]interface{}
 	if len(args) > 2 {
-		argsByServer, err = resolveArgsByServer(serverIDs, args[2:len(args)])
+		argsByServer, err = resolveArgsByServer(ctx, serverIDs, args[2:len(args)])
 		if err != nil {
 			return err
 		}
 	}
 
-	method, err := removeNamespace(request.MethodName)
+	method, err := removeNamespace(ctx, request.MethodName)
 	if err != nil {
 		return err
 	}
 
-	*parsedRequest = controller.MulticastRequest{method, hubSessionKey, serverIDs, argsByServer}
+	*parsedRequest = controller.MulticastRequest{method, hubSessionKey, serverIDs, argsByServer, options}
 	return nil
 }
 
-func resolveServerIDs(args interface{}) ([]int64, error) {
+func resolveServerIDs(ctx context.Context, args interface{}) ([]int64, error) {
 	serverIDs, ok := args.([]interface{})
 	if !ok {
-		log.Printf("Error ocurred when parsing serverIDs argument")
+		logging.Error(ctx, "Error ocurred when parsing serverIDs argument")
 		return nil, controller.FaultInvalidParams
 	}
 
@@ -60,7 +70,7 @@ func resolveServerIDs(args interface{}) ([]int64, error) {
 	for _, serverID := range serverIDs {
 		parsedServerID, ok := serverID.(int64)
 		if !ok {
-			log.Printf("Error ocurred when parsing serverIDs argument")
+			logging.Error(ctx, "Error ocurred when parsing serverIDs argument")
 			return nil, controller.FaultInvalidParams
 		}
 		parsedServerIDs = append(parsedServerIDs, parsedServerID)
@@ -68,7 +78,47 @@ func resolveServerIDs(args interface{}) ([]int64, error) {
 	return parsedServerIDs, nil
 }
 
-func resolveArgsByServer(serverIDs []int64, allServerArgs []interface{}) (map[int64][]interface{}, error) {
+func resolveMulticastOptions(ctx context.Context, rawOptions map[string]interface{}) (controller.MulticastOptions, error) {
+	var options controller.MulticastOptions
+	for name, value := range rawOptions {
+		switch name {
+		case "maxConcurrency":
+			maxConcurrency, ok := value.(int64)
+			if !ok || maxConcurrency < 0 {
+				logging.Error(ctx, "Error ocurred when parsing maxConcurrency option")
+				return options, controller.FaultInvalidParams
+			}
+			options.MaxConcurrency = int(maxConcurrency)
+		case "timeout":
+			timeout, ok := value.(int64)
+			if !ok || timeout < 0 {
+				logging.Error(ctx, "Error ocurred when parsing timeout option")
+				return options, controller.FaultInvalidParams
+			}
+			options.Timeout = int(timeout)
+		case "legacyFailedResponses":
+			legacyFailedResponses, ok := value.(bool)
+			if !ok {
+				logging.Error(ctx, "Error ocurred when parsing legacyFailedResponses option")
+				return options, controller.FaultInvalidParams
+			}
+			options.LegacyFailedResponses = legacyFailedResponses
+		case "keyedResults":
+			keyedResults, ok := value.(bool)
+			if !ok {
+				logging.Error(ctx, "Error ocurred when parsing keyedResults option")
+				return options, controller.FaultInvalidParams
+			}
+			options.KeyedResults = keyedResults
+		default:
+			logging.Error(ctx, "Unknown multicast option", "option", name)
+			return options, controller.FaultInvalidParams
+		}
+	}
+	return options, nil
+}
+
+func resolveArgsByServer(ctx context.Context, serverIDs []int64, allServerArgs []interface{}) (map[int64][]interface{}, error) {
 	result := make(map[int64][]interface{})
 	for i, serverID := range serverIDs {
 		args := make([]interface{}, 0, len(allServerArgs)+1)
@@ -76,7 +126,7 @@ func resolveArgsByServer(serverIDs []int64, allServerArgs []interface{}) (map[in
 		for _, serverArgs := range allServerArgs {
 			parsedServerArgs, ok := serverArgs.([]interface{})
 			if !ok {
-				log.Printf("Error ocurred when parsing server arguments")
+				logging.Error(ctx, "Error ocurred when parsing server arguments")
 				return nil, controller.FaultInvalidParams
 			}
 			args = append(args, parsedServerArgs[i])
@@ -86,10 +136,10 @@ func resolveArgsByServer(serverIDs []int64, allServerArgs []interface{}) (map[in
 	return result, nil
 }
 
-func removeNamespace(method string) (string, error) {
+func removeNamespace(ctx context.Context, method string) (string, error) {
 	parts := strings.Split(method, ".")
 	if len(parts) <= 1 {
-		log.Printf("Namespace not found")
+		logging.Error(ctx, "Namespace not found")
 		return "", controller.FaultDecode
 	}
 
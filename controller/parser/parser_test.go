@@ -1,12 +1,14 @@
 package parser
 
 import (
+	"context"
 	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/controller"
 	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
 )
 
 func Test_LoginRequestParser(t *testing.T) {
@@ -42,7 +44,7 @@ func Test_LoginRequestParser(t *testing.T) {
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			err := LoginRequestParser(tc.serverRequest, tc.requestToHydrate)
+			err := LoginRequestParser(context.Background(), tc.serverRequest, tc.requestToHydrate)
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
 			}
@@ -78,7 +80,7 @@ func Test_ProxyCallToHubRequestParser(t *testing.T) {
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			err := ProxyCallToHubRequestParser(tc.serverRequest, tc.requestToHydrate)
+			err := ProxyCallToHubRequestParser(context.Background(), tc.serverRequest, tc.requestToHydrate)
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
 			}
@@ -109,6 +111,22 @@ func Test_MulticastRequestParser(t *testing.T) {
 			serverRequest:    &xmlrpc.ServerRequest{"multicast.method", []interface{}{"hubSessionKey", []interface{}{int64(1), int64(2)}, []interface{}{"arg1_Server1", "arg1_Server2"}, []interface{}{nil, nil}}},
 			requestToHydrate: &controller.MulticastRequest{},
 			expectedRequest:  controller.MulticastRequest{Call: "method", HubSessionKey: "hubSessionKey", ServerIDs: []int64{1, 2}, ArgsByServer: map[int64][]interface{}{1: []interface{}{"arg1_Server1", nil}, 2: []interface{}{"arg1_Server2", nil}}}},
+		{name: "MulticastRequestParser options_should_succeed",
+			serverRequest:    &xmlrpc.ServerRequest{"multicast.method", []interface{}{"hubSessionKey", []interface{}{int64(1), int64(2)}, []interface{}{"arg1_Server1", "arg1_Server2"}, map[string]interface{}{"maxConcurrency": int64(1), "timeout": int64(30)}}},
+			requestToHydrate: &controller.MulticastRequest{},
+			expectedRequest:  controller.MulticastRequest{Call: "method", HubSessionKey: "hubSessionKey", ServerIDs: []int64{1, 2}, ArgsByServer: map[int64][]interface{}{1: []interface{}{"arg1_Server1"}, 2: []interface{}{"arg1_Server2"}}, Options: controller.MulticastOptions{MaxConcurrency: 1, Timeout: 30}}},
+		{name: "MulticastRequestParser result_format_options_should_succeed",
+			serverRequest:    &xmlrpc.ServerRequest{"multicast.method", []interface{}{"hubSessionKey", []interface{}{int64(1)}, []interface{}{"arg1_Server1"}, map[string]interface{}{"legacyFailedResponses": true, "keyedResults": true}}},
+			requestToHydrate: &controller.MulticastRequest{},
+			expectedRequest:  controller.MulticastRequest{Call: "method", HubSessionKey: "hubSessionKey", ServerIDs: []int64{1}, ArgsByServer: map[int64][]interface{}{1: []interface{}{"arg1_Server1"}}, Options: controller.MulticastOptions{LegacyFailedResponses: true, KeyedResults: true}}},
+		{name: "MulticastRequestParser negative_timeout_should_fail",
+			serverRequest:    &xmlrpc.ServerRequest{"multicast.method", []interface{}{"hubSessionKey", []interface{}{int64(1)}, []interface{}{"arg1_Server1"}, map[string]interface{}{"timeout": int64(-1)}}},
+			requestToHydrate: &controller.MulticastRequest{},
+			expectedError:    controller.FaultInvalidParams.Message},
+		{name: "MulticastRequestParser unknown_option_should_fail",
+			serverRequest:    &xmlrpc.ServerRequest{"multicast.method", []interface{}{"hubSessionKey", []interface{}{int64(1)}, map[string]interface{}{"unknown": int64(1)}}},
+			requestToHydrate: &controller.MulticastRequest{},
+			expectedError:    controller.FaultInvalidParams.Message},
 		{name: "MulticastRequestParser no_serverID_passed_should_fail",
 			serverRequest:    &xmlrpc.ServerRequest{"multicast.method", []interface{}{"hubSessionKey", []interface{}{"serverSessionKey"}}},
 			requestToHydrate: &controller.MulticastRequest{},
@@ -137,7 +155,7 @@ func Test_MulticastRequestParser(t *testing.T) {
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			err := MulticastRequestParser(tc.serverRequest, tc.requestToHydrate)
+			err := MulticastRequestParser(context.Background(), tc.serverRequest, tc.requestToHydrate)
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
 			}
@@ -192,7 +210,7 @@ func Test_UnicastRequestParser(t *testing.T) {
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			err := UnicastRequestParser(tc.serverRequest, tc.requestToHydrate)
+			err := UnicastRequestParser(context.Background(), tc.serverRequest, tc.requestToHydrate)
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
 			}
@@ -202,3 +220,261 @@ func Test_UnicastRequestParser(t *testing.T) {
 		})
 	}
 }
+
+func Test_SubmitMulticastJobRequestParser(t *testing.T) {
+	tt := []struct {
+		name            string
+		serverRequest   *xmlrpc.ServerRequest
+		expectedRequest controller.MulticastRequest
+		expectedError   string
+	}{
+		{name: "SubmitMulticastJobRequestParser should_succeed",
+			serverRequest: &xmlrpc.ServerRequest{"hub.submitMulticastJob", []interface{}{"sessionKey", "system.listSystems", []interface{}{int64(1), int64(2)}, []interface{}{"arg1_Server1", "arg1_Server2"}, map[string]interface{}{"maxConcurrency": int64(1)}}},
+			expectedRequest: controller.MulticastRequest{Call: "system.listSystems", HubSessionKey: "sessionKey", ServerIDs: []int64{1, 2},
+				ArgsByServer: map[int64][]interface{}{1: {"arg1_Server1"}, 2: {"arg1_Server2"}}, Options: controller.MulticastOptions{MaxConcurrency: 1}}},
+		{name: "SubmitMulticastJobRequestParser wrong_number_of_arguments Failed",
+			serverRequest: &xmlrpc.ServerRequest{"hub.submitMulticastJob", []interface{}{"sessionKey", "system.listSystems"}},
+			expectedError: controller.FaultWrongArgumentsNumber.Message},
+		{name: "SubmitMulticastJobRequestParser malformed_call_should_fail",
+			serverRequest: &xmlrpc.ServerRequest{"hub.submitMulticastJob", []interface{}{"sessionKey", int64(1), []interface{}{int64(1), int64(2)}}},
+			expectedError: controller.FaultInvalidParams.Message},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			requestToHydrate := &controller.MulticastRequest{}
+			err := SubmitMulticastJobRequestParser(context.Background(), tc.serverRequest, requestToHydrate)
+			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
+				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
+			}
+			if err == nil && !reflect.DeepEqual(requestToHydrate, &tc.expectedRequest) {
+				t.Fatalf("expected and actual requests don't match. Expected was:\n%v\nActual is:\n%v", &tc.expectedRequest, requestToHydrate)
+			}
+		})
+	}
+}
+
+func Test_MulticastJobRequestParser(t *testing.T) {
+	tt := []struct {
+		name            string
+		serverRequest   *xmlrpc.ServerRequest
+		expectedRequest controller.MulticastJobRequest
+		expectedError   string
+	}{
+		{name: "MulticastJobRequestParser should_succeed",
+			serverRequest:   &xmlrpc.ServerRequest{"hub.getJobResult", []interface{}{"sessionKey", "jobID"}},
+			expectedRequest: controller.MulticastJobRequest{HubSessionKey: "sessionKey", JobID: "jobID"}},
+		{name: "MulticastJobRequestParser options_should_succeed",
+			serverRequest:   &xmlrpc.ServerRequest{"hub.getJobResult", []interface{}{"sessionKey", "jobID", map[string]interface{}{"legacyFailedResponses": true}}},
+			expectedRequest: controller.MulticastJobRequest{HubSessionKey: "sessionKey", JobID: "jobID", Options: controller.MulticastOptions{LegacyFailedResponses: true}}},
+		{name: "MulticastJobRequestParser wrong_number_of_arguments Failed",
+			serverRequest: &xmlrpc.ServerRequest{"hub.getJobResult", []interface{}{"sessionKey"}},
+			expectedError: controller.FaultWrongArgumentsNumber.Message},
+		{name: "MulticastJobRequestParser malformed_options_should_fail",
+			serverRequest: &xmlrpc.ServerRequest{"hub.getJobResult", []interface{}{"sessionKey", "jobID", map[string]interface{}{"legacyFailedResponses": "yes"}}},
+			expectedError: controller.FaultInvalidParams.Message},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			requestToHydrate := &controller.MulticastJobRequest{}
+			err := MulticastJobRequestParser(context.Background(), tc.serverRequest, requestToHydrate)
+			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
+				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
+			}
+			if err == nil && !reflect.DeepEqual(requestToHydrate, &tc.expectedRequest) {
+				t.Fatalf("expected and actual requests don't match. Expected was:\n%v\nActual is:\n%v", &tc.expectedRequest, requestToHydrate)
+			}
+		})
+	}
+}
+
+func Test_DetachFromServersRequestParser(t *testing.T) {
+	tt := []struct {
+		name            string
+		serverRequest   *xmlrpc.ServerRequest
+		expectedRequest controller.DetachFromServersRequest
+		expectedError   string
+	}{
+		{name: "DetachFromServersRequestParser should_succeed",
+			serverRequest:   &xmlrpc.ServerRequest{"hub.detachFromServers", []interface{}{"sessionKey", []interface{}{int64(1), int64(2)}}},
+			expectedRequest: controller.DetachFromServersRequest{HubSessionKey: "sessionKey", ServerIDs: []int64{1, 2}}},
+		{name: "DetachFromServersRequestParser options_should_succeed",
+			serverRequest: &xmlrpc.ServerRequest{"hub.detachFromServers", []interface{}{"sessionKey", []interface{}{int64(1)}, map[string]interface{}{"legacyFailedResponses": true}}},
+			expectedRequest: controller.DetachFromServersRequest{HubSessionKey: "sessionKey", ServerIDs: []int64{1},
+				Options: controller.ServerAuthenticationOptions{LegacyFailedResponses: true}}},
+		{name: "DetachFromServersRequestParser unknown_option_should_fail",
+			serverRequest: &xmlrpc.ServerRequest{"hub.detachFromServers", []interface{}{"sessionKey", []interface{}{int64(1)}, map[string]interface{}{"keyedResults": true}}},
+			expectedError: controller.FaultInvalidParams.Message},
+		{name: "DetachFromServersRequestParser wrong_number_of_arguments Failed",
+			serverRequest: &xmlrpc.ServerRequest{"hub.detachFromServers", []interface{}{"sessionKey"}},
+			expectedError: controller.FaultWrongArgumentsNumber.Message},
+		{name: "DetachFromServersRequestParser malformed_serverIDs_should_fail",
+			serverRequest: &xmlrpc.ServerRequest{"hub.detachFromServers", []interface{}{"sessionKey", []interface{}{"1"}}},
+			expectedError: controller.FaultInvalidParams.Message},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			requestToHydrate := &controller.DetachFromServersRequest{}
+			err := DetachFromServersRequestParser(context.Background(), tc.serverRequest, requestToHydrate)
+			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
+				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
+			}
+			if err == nil && !reflect.DeepEqual(requestToHydrate, &tc.expectedRequest) {
+				t.Fatalf("expected and actual requests don't match. Expected was:\n%v\nActual is:\n%v", &tc.expectedRequest, requestToHydrate)
+			}
+		})
+	}
+}
+
+func Test_AttachToServersRequestParser(t *testing.T) {
+	tt := []struct {
+		name            string
+		serverRequest   *xmlrpc.ServerRequest
+		expectedRequest controller.AttachToServersRequest
+		expectedError   string
+	}{
+		{name: "AttachToServersRequestParser should_succeed",
+			serverRequest:   &xmlrpc.ServerRequest{"hub.attachToServers", []interface{}{"sessionKey", []interface{}{int64(1)}}},
+			expectedRequest: controller.AttachToServersRequest{HubSessionKey: "sessionKey", ServerIDs: []int64{1}}},
+		{name: "AttachToServersRequestParser options_should_succeed",
+			serverRequest: &xmlrpc.ServerRequest{"hub.attachToServers", []interface{}{"sessionKey", []interface{}{int64(1)}, map[string]interface{}{"legacyFailedResponses": true}}},
+			expectedRequest: controller.AttachToServersRequest{HubSessionKey: "sessionKey", ServerIDs: []int64{1},
+				Options: controller.ServerAuthenticationOptions{LegacyFailedResponses: true}}},
+		{name: "AttachToServersRequestParser credentials_and_options_should_succeed",
+			serverRequest: &xmlrpc.ServerRequest{"hub.attachToServers", []interface{}{"sessionKey", []interface{}{int64(1)}, []interface{}{"username"}, []interface{}{"password"},
+				map[string]interface{}{"legacyFailedResponses": true}}},
+			expectedRequest: controller.AttachToServersRequest{HubSessionKey: "sessionKey", ServerIDs: []int64{1},
+				CredentialsByServer: map[int64]*gateway.Credentials{1: {"username", "password"}},
+				Options:             controller.ServerAuthenticationOptions{LegacyFailedResponses: true}}},
+		{name: "AttachToServersRequestParser malformed_options_should_fail",
+			serverRequest: &xmlrpc.ServerRequest{"hub.attachToServers", []interface{}{"sessionKey", []interface{}{int64(1)}, map[string]interface{}{"legacyFailedResponses": "yes"}}},
+			expectedError: controller.FaultInvalidParams.Message},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			requestToHydrate := &controller.AttachToServersRequest{}
+			err := AttachToServersRequestParser(context.Background(), tc.serverRequest, requestToHydrate)
+			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
+				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
+			}
+			if err == nil && !reflect.DeepEqual(requestToHydrate, &tc.expectedRequest) {
+				t.Fatalf("expected and actual requests don't match. Expected was:\n%v\nActual is:\n%v", &tc.expectedRequest, requestToHydrate)
+			}
+		})
+	}
+}
+
+func Test_RetryFailedAttachmentsRequestParser(t *testing.T) {
+	tt := []struct {
+		name            string
+		serverRequest   *xmlrpc.ServerRequest
+		expectedRequest controller.RetryFailedAttachmentsRequest
+		expectedError   string
+	}{
+		{name: "RetryFailedAttachmentsRequestParser should_succeed",
+			serverRequest:   &xmlrpc.ServerRequest{"hub.retryFailedAttachments", []interface{}{"sessionKey"}},
+			expectedRequest: controller.RetryFailedAttachmentsRequest{HubSessionKey: "sessionKey"}},
+		{name: "RetryFailedAttachmentsRequestParser options_should_succeed",
+			serverRequest:   &xmlrpc.ServerRequest{"hub.retryFailedAttachments", []interface{}{"sessionKey", map[string]interface{}{"legacyFailedResponses": true}}},
+			expectedRequest: controller.RetryFailedAttachmentsRequest{HubSessionKey: "sessionKey", Options: controller.ServerAuthenticationOptions{LegacyFailedResponses: true}}},
+		{name: "RetryFailedAttachmentsRequestParser wrong_number_of_arguments Failed",
+			serverRequest: &xmlrpc.ServerRequest{"hub.retryFailedAttachments", []interface{}{}},
+			expectedError: controller.FaultWrongArgumentsNumber.Message},
+		{name: "RetryFailedAttachmentsRequestParser malformed_options_should_fail",
+			serverRequest: &xmlrpc.ServerRequest{"hub.retryFailedAttachments", []interface{}{"sessionKey", "options"}},
+			expectedError: controller.FaultInvalidParams.Message},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			requestToHydrate := &controller.RetryFailedAttachmentsRequest{}
+			err := RetryFailedAttachmentsRequestParser(context.Background(), tc.serverRequest, requestToHydrate)
+			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
+				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
+			}
+			if err == nil && !reflect.DeepEqual(requestToHydrate, &tc.expectedRequest) {
+				t.Fatalf("expected and actual requests don't match. Expected was:\n%v\nActual is:\n%v", &tc.expectedRequest, requestToHydrate)
+			}
+		})
+	}
+}
+
+func Test_LoginWithAutoconnectModeRequestParser(t *testing.T) {
+	tt := []struct {
+		name            string
+		serverRequest   *xmlrpc.ServerRequest
+		expectedRequest controller.LoginWithAutoconnectModeRequest
+		expectedError   string
+	}{
+		{name: "LoginWithAutoconnectModeRequestParser should_succeed",
+			serverRequest:   &xmlrpc.ServerRequest{"hub.loginWithAutoconnectMode", []interface{}{"username", "password"}},
+			expectedRequest: controller.LoginWithAutoconnectModeRequest{Username: "username", Password: "password"}},
+		{name: "LoginWithAutoconnectModeRequestParser options_should_succeed",
+			serverRequest: &xmlrpc.ServerRequest{"hub.loginWithAutoconnectMode", []interface{}{"username", "password", map[string]interface{}{"legacyFailedResponses": true}}},
+			expectedRequest: controller.LoginWithAutoconnectModeRequest{Username: "username", Password: "password",
+				Options: controller.ServerAuthenticationOptions{LegacyFailedResponses: true}}},
+		{name: "LoginWithAutoconnectModeRequestParser wrong_number_of_arguments Failed",
+			serverRequest: &xmlrpc.ServerRequest{"hub.loginWithAutoconnectMode", []interface{}{"username"}},
+			expectedError: controller.FaultWrongArgumentsNumber.Message},
+		{name: "LoginWithAutoconnectModeRequestParser wrong_type_of_arguments_passed_should_fail",
+			serverRequest: &xmlrpc.ServerRequest{"hub.loginWithAutoconnectMode", []interface{}{"username", 123}},
+			expectedError: controller.FaultInvalidParams.Message},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			requestToHydrate := &controller.LoginWithAutoconnectModeRequest{}
+			err := LoginWithAutoconnectModeRequestParser(context.Background(), tc.serverRequest, requestToHydrate)
+			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
+				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
+			}
+			if err == nil && !reflect.DeepEqual(requestToHydrate, &tc.expectedRequest) {
+				t.Fatalf("expected and actual requests don't match. Expected was:\n%v\nActual is:\n%v", &tc.expectedRequest, requestToHydrate)
+			}
+		})
+	}
+}
+
+func Test_MulticallRequestParser(t *testing.T) {
+	tt := []struct {
+		name            string
+		serverRequest   *xmlrpc.ServerRequest
+		expectedRequest controller.MulticallRequest
+		expectedError   string
+	}{
+		{name: "MulticallRequestParser should_succeed",
+			serverRequest: &xmlrpc.ServerRequest{"system.multicall", []interface{}{[]interface{}{
+				map[string]interface{}{"methodName": "unicast.system.listSystems", "params": []interface{}{"sessionKey", int64(1)}},
+				map[string]interface{}{"methodName": "hub.listServerIds"},
+			}}},
+			expectedRequest: controller.MulticallRequest{Calls: []controller.MulticallCall{
+				{MethodName: "unicast.system.listSystems", Params: []interface{}{"sessionKey", int64(1)}},
+				{MethodName: "hub.listServerIds"},
+			}}},
+		{name: "MulticallRequestParser wrong_number_of_arguments Failed",
+			serverRequest: &xmlrpc.ServerRequest{"system.multicall", []interface{}{}},
+			expectedError: controller.FaultWrongArgumentsNumber.Message},
+		{name: "MulticallRequestParser malformed_call_should_fail",
+			serverRequest: &xmlrpc.ServerRequest{"system.multicall", []interface{}{[]interface{}{map[string]interface{}{"methodName": int64(1)}}}},
+			expectedError: controller.FaultInvalidParams.Message},
+		{name: "MulticallRequestParser malformed_params_should_fail",
+			serverRequest: &xmlrpc.ServerRequest{"system.multicall", []interface{}{[]interface{}{map[string]interface{}{"methodName": "hub.listServerIds", "params": "sessionKey"}}}},
+			expectedError: controller.FaultInvalidParams.Message},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			requestToHydrate := &controller.MulticallRequest{}
+			err := MulticallRequestParser(context.Background(), tc.serverRequest, requestToHydrate)
+			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
+				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
+			}
+			if err == nil && !reflect.DeepEqual(requestToHydrate, &tc.expectedRequest) {
+				t.Fatalf("expected and actual requests don't match. Expected was:\n%v\nActual is:\n%v", &tc.expectedRequest, requestToHydrate)
+			}
+		})
+	}
+}
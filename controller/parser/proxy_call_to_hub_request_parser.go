@@ -1,16 +1,17 @@
 package parser
 
 import (
-	"log"
+	"context"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/controller"
 	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
-func ProxyCallToHubRequestParser(request *xmlrpc.ServerRequest, output interface{}) error {
+func ProxyCallToHubRequestParser(ctx context.Context, request *xmlrpc.ServerRequest, output interface{}) error {
 	parsedArgs, ok := output.(*controller.ProxyCallToHubRequest)
 	if !ok {
-		log.Printf("Error ocurred when parsing arguments")
+		logging.Error(ctx, "Error ocurred when parsing arguments")
 		return controller.FaultInvalidParams
 	}
 	*parsedArgs = controller.ProxyCallToHubRequest{request.MethodName, request.Params}
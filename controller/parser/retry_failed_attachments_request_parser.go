@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+func RetryFailedAttachmentsRequestParser(ctx context.Context, request *xmlrpc.ServerRequest, output interface{}) error {
+	parsedRequest, ok := output.(*controller.RetryFailedAttachmentsRequest)
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing arguments")
+		return controller.FaultInvalidParams
+	}
+
+	args := request.Params
+	if len(args) != 1 && len(args) != 2 {
+		logging.Error(ctx, "Error ocurred when parsing arguments")
+		return controller.FaultWrongArgumentsNumber
+	}
+
+	hubSessionKey, ok := args[0].(string)
+	if !ok {
+		logging.Error(ctx, "Error ocurred when parsing hubSessionKey argument")
+		return controller.FaultInvalidParams
+	}
+
+	var options controller.ServerAuthenticationOptions
+	if len(args) == 2 {
+		rawOptions, ok := args[1].(map[string]interface{})
+		if !ok {
+			logging.Error(ctx, "Error ocurred when parsing options argument")
+			return controller.FaultInvalidParams
+		}
+		var err error
+		options, err = resolveServerAuthenticationOptions(ctx, rawOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	*parsedRequest = controller.RetryFailedAttachmentsRequest{hubSessionKey, options}
+	return nil
+}
@@ -0,0 +1,28 @@
+package parser
+
+import (
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+//SubmitMulticastJobRequestParser parses the same arguments as a multicast call, with the name of the method to call
+//right after the hubSessionKey
+func SubmitMulticastJobRequestParser(ctx context.Context, request *xmlrpc.ServerRequest, output interface{}) error {
+	args := request.Params
+	if len(args) < 3 {
+		logging.Error(ctx, "Error ocurred when parsing arguments")
+		return controller.FaultWrongArgumentsNumber
+	}
+
+	call, ok := args[1].(string)
+	if !ok || call == "" {
+		logging.Error(ctx, "Error ocurred when parsing call argument")
+		return controller.FaultInvalidParams
+	}
+
+	multicastArgs := append([]interface{}{args[0]}, args[2:]...)
+	return MulticastRequestParser(ctx, &xmlrpc.ServerRequest{"multicast." + call, multicastArgs}, output)
+}
@@ -1,34 +1,35 @@
 package parser
 
 import (
-	"log"
+	"context"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/controller"
 	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
-func UnicastRequestParser(request *xmlrpc.ServerRequest, output interface{}) error {
+func UnicastRequestParser(ctx context.Context, request *xmlrpc.ServerRequest, output interface{}) error {
 	parsedArgs, ok := output.(*controller.UnicastRequest)
 	if !ok {
-		log.Printf("Error ocurred when parsing arguments")
+		logging.Error(ctx, "Error ocurred when parsing arguments")
 		return controller.FaultInvalidParams
 	}
 
 	args := request.Params
 	if len(args) < 2 {
-		log.Printf("Error ocurred when parsing arguments")
+		logging.Error(ctx, "Error ocurred when parsing arguments")
 		return controller.FaultWrongArgumentsNumber
 	}
 
 	hubSessionKey, ok := args[0].(string)
 	if !ok {
-		log.Printf("Error ocurred when parsing hubSessionKey argument")
+		logging.Error(ctx, "Error ocurred when parsing hubSessionKey argument")
 		return controller.FaultInvalidParams
 	}
 
 	serverID, ok := args[1].(int64)
 	if !ok {
-		log.Printf("Error ocurred when parsing serverID argument")
+		logging.Error(ctx, "Error ocurred when parsing serverID argument")
 		return controller.FaultInvalidParams
 	}
 
@@ -38,7 +39,7 @@ func UnicastRequestParser(request *xmlrpc.ServerRequest, output interface{}) err
 		serverArgs[i] = (interface{})(arg)
 	}
 
-	method, err := removeNamespace(request.MethodName)
+	method, err := removeNamespace(ctx, request.MethodName)
 	if err != nil {
 		return err
 	}
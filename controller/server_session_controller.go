@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+type ServerSessionController struct {
+	serverSessionPinger gateway.ServerSessionPinger
+}
+
+func NewServerSessionController(serverSessionPinger gateway.ServerSessionPinger) *ServerSessionController {
+	return &ServerSessionController{serverSessionPinger}
+}
+
+func (h *ServerSessionController) ListServerSessionStates(r *http.Request, args *struct{ HubSessionKey string }, reply *struct {
+	Data []gateway.ServerSessionState
+}) error {
+	states, err := h.serverSessionPinger.ListServerSessionStates(r.Context(), args.HubSessionKey)
+	if err != nil {
+		logging.Error(r.Context(), "Error ocurred while retrieving server session states", "error", err)
+		return err
+	}
+	reply.Data = states
+	return nil
+}
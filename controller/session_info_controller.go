@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+type SessionInfoController struct {
+	sessionInfoRetriever gateway.SessionInfoRetriever
+}
+
+func NewSessionInfoController(sessionInfoRetriever gateway.SessionInfoRetriever) *SessionInfoController {
+	return &SessionInfoController{sessionInfoRetriever}
+}
+
+func (h *SessionInfoController) GetSessionInfo(r *http.Request, args *struct{ HubSessionKey string }, reply *struct {
+	Data *gateway.SessionInfo
+}) error {
+	sessionInfo, err := h.sessionInfoRetriever.GetSessionInfo(r.Context(), args.HubSessionKey)
+	if err != nil {
+		logging.Error(r.Context(), "Error ocurred while retrieving session info", "error", err)
+		return err
+	}
+	reply.Data = sessionInfo
+	return nil
+}
@@ -1,10 +1,10 @@
 package controller
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
 type HubTopologyController struct {
@@ -12,9 +12,9 @@ type HubTopologyController struct {
 }
 
 func (h *HubTopologyController) ListServerIDs(r *http.Request, args *struct{ HubSessionKey string }, reply *struct{ Data []int64 }) error {
-	serverIDs, err := h.hubService.ListServerIDs(args.HubSessionKey)
+	serverIDs, err := h.hubService.ListServerIDs(r.Context(), args.HubSessionKey)
 	if err != nil {
-		log.Printf("Login error: %v", err)
+		logging.Error(r.Context(), "Login error", "error", err)
 		return err
 	}
 	reply.Data = serverIDs
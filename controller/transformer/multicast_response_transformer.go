@@ -1,36 +1,96 @@
 package transformer
 
 import (
+	"sort"
+
 	"github.com/uyuni-project/hub-xmlrpc-api/controller"
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
 )
 
-// MulticastResponseTransformer turns a multicast response from the gateway to the controller format
-func MulticastResponseTransformer(multicastResponse *gateway.MulticastResponse) *controller.MulticastResponse {
+// MulticastResponseTransformer turns a multicast response from the gateway to the controller format.
+// The responses follow the order in which the servers were requested.
+// Failed calls are described with a controller.FailedResponse, or only with their error message if legacyFailedResponses is set
+func MulticastResponseTransformer(multicastResponse *gateway.MulticastResponse, legacyFailedResponses bool) *controller.MulticastResponse {
+	orderedServerIDs := orderServerIDs(multicastResponse)
 	return &controller.MulticastResponse{
-		transformToSuccessfulResponses(multicastResponse.SuccessfulResponses),
-		transformToFailedResponses(multicastResponse.FailedResponses),
+		transformToSuccessfulResponses(orderedServerIDs, multicastResponse.SuccessfulResponses),
+		transformToFailedResponses(orderedServerIDs, multicastResponse.FailedResponses, legacyFailedResponses),
 	}
 }
 
-func transformToSuccessfulResponses(serverCallResponses map[int64]gateway.ServerSuccessfulResponse) controller.MulticastStateResponse {
+//orderServerIDs returns the requested server IDs, followed by the IDs of any other server in the response sorted in ascending order
+func orderServerIDs(multicastResponse *gateway.MulticastResponse) []int64 {
+	orderedServerIDs := make([]int64, 0, len(multicastResponse.ServerIDs))
+	seen := make(map[int64]bool)
+	for _, serverID := range multicastResponse.ServerIDs {
+		if !seen[serverID] {
+			seen[serverID] = true
+			orderedServerIDs = append(orderedServerIDs, serverID)
+		}
+	}
+	otherServerIDs := make([]int64, 0)
+	for serverID := range multicastResponse.SuccessfulResponses {
+		if !seen[serverID] {
+			seen[serverID] = true
+			otherServerIDs = append(otherServerIDs, serverID)
+		}
+	}
+	for serverID := range multicastResponse.FailedResponses {
+		if !seen[serverID] {
+			seen[serverID] = true
+			otherServerIDs = append(otherServerIDs, serverID)
+		}
+	}
+	sort.Slice(otherServerIDs, func(i, j int) bool { return otherServerIDs[i] < otherServerIDs[j] })
+	return append(orderedServerIDs, otherServerIDs...)
+}
+
+func transformToSuccessfulResponses(orderedServerIDs []int64, serverCallResponses map[int64]gateway.ServerSuccessfulResponse) controller.MulticastStateResponse {
 	serverIDs := make([]int64, 0, len(serverCallResponses))
 	responses := make([]interface{}, 0, len(serverCallResponses))
+	attempts := make([]int, 0, len(serverCallResponses))
 
-	for serverID, response := range serverCallResponses {
+	for _, serverID := range orderedServerIDs {
+		response, ok := serverCallResponses[serverID]
+		if !ok {
+			continue
+		}
 		serverIDs = append(serverIDs, serverID)
 		responses = append(responses, response.Response)
+		attempts = append(attempts, response.Attempts)
 	}
-	return controller.MulticastStateResponse{serverIDs, responses}
+	return controller.MulticastStateResponse{serverIDs, responses, attempts}
 }
 
-func transformToFailedResponses(serverCallResponses map[int64]gateway.ServerFailedResponse) controller.MulticastStateResponse {
+func transformToFailedResponses(orderedServerIDs []int64, serverCallResponses map[int64]gateway.ServerFailedResponse, legacyFailedResponses bool) controller.MulticastStateResponse {
 	serverIDs := make([]int64, 0, len(serverCallResponses))
 	responses := make([]interface{}, 0, len(serverCallResponses))
+	attempts := make([]int, 0, len(serverCallResponses))
 
-	for serverID, response := range serverCallResponses {
+	for _, serverID := range orderedServerIDs {
+		response, ok := serverCallResponses[serverID]
+		if !ok {
+			continue
+		}
 		serverIDs = append(serverIDs, serverID)
-		responses = append(responses, response.ErrorMessage)
+		if legacyFailedResponses {
+			responses = append(responses, response.ErrorMessage)
+		} else {
+			responses = append(responses, transformToFailedResponse(response))
+		}
+		attempts = append(attempts, response.Attempts)
+	}
+	return controller.MulticastStateResponse{serverIDs, responses, attempts}
+}
+
+func transformToFailedResponse(response gateway.ServerFailedResponse) controller.FailedResponse {
+	fault := controller.ToFaultError(response.Err)
+	return controller.FailedResponse{
+		fault.Code,
+		fault.Message,
+		controller.ErrorCategory(response.Err),
+		response.Endpoint(),
+		response.Attempts,
+		response.Elapsed.Milliseconds(),
 	}
-	return controller.MulticastStateResponse{serverIDs, responses}
 }
@@ -0,0 +1,56 @@
+package transformer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+)
+
+func Test_MulticastResponseTransformer(t *testing.T) {
+	multicastResponse := &gateway.MulticastResponse{
+		ServerIDs: []int64{3, 1, 2},
+		SuccessfulResponses: map[int64]gateway.ServerSuccessfulResponse{
+			1: {ServerID: 1, Response: "response_1", Attempts: 1},
+			3: {ServerID: 3, Response: "response_3", Attempts: 2},
+			5: {ServerID: 5, Response: "response_5", Attempts: 1},
+			4: {ServerID: 4, Response: "response_4", Attempts: 1},
+		},
+		FailedResponses: map[int64]gateway.ServerFailedResponse{
+			2: {ServerID: 2, ErrorMessage: "call_error", Attempts: 3, Err: errors.New("call_error")},
+		},
+	}
+
+	tt := []struct {
+		name                      string
+		legacyFailedResponses     bool
+		expectedMulticastResponse *controller.MulticastResponse
+	}{
+		{
+			name: "MulticastResponseTransformer failed_responses",
+			expectedMulticastResponse: &controller.MulticastResponse{
+				controller.MulticastStateResponse{[]int64{3, 1, 4, 5}, []interface{}{"response_3", "response_1", "response_4", "response_5"}, []int{2, 1, 1, 1}},
+				controller.MulticastStateResponse{[]int64{2}, []interface{}{controller.FailedResponse{-32500, "Application Error: call_error", "error", "", 3, 0}}, []int{3}},
+			},
+		},
+		{
+			name:                  "MulticastResponseTransformer legacy_failed_responses",
+			legacyFailedResponses: true,
+			expectedMulticastResponse: &controller.MulticastResponse{
+				controller.MulticastStateResponse{[]int64{3, 1, 4, 5}, []interface{}{"response_3", "response_1", "response_4", "response_5"}, []int{2, 1, 1, 1}},
+				controller.MulticastStateResponse{[]int64{2}, []interface{}{"call_error"}, []int{3}},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			response := MulticastResponseTransformer(multicastResponse, tc.legacyFailedResponses)
+			if !reflect.DeepEqual(response, tc.expectedMulticastResponse) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", response, tc.expectedMulticastResponse)
+			}
+		})
+	}
+}
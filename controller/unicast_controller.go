@@ -1,10 +1,10 @@
 package controller
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
 type UnicastController struct {
@@ -23,9 +23,14 @@ type UnicastRequest struct {
 }
 
 func (u *UnicastController) Unicast(r *http.Request, args *UnicastRequest, reply *struct{ Data interface{} }) error {
-	response, err := u.unicaster.Unicast(args.HubSessionKey, args.Call, args.ServerID, args.Args)
+	ctx, cancel, err := callContext(r, 0)
 	if err != nil {
-		log.Printf("Call error: %v", err)
+		return err
+	}
+	defer cancel()
+	response, err := u.unicaster.Unicast(ctx, args.HubSessionKey, args.Call, args.ServerID, args.Args)
+	if err != nil {
+		logging.Error(r.Context(), "Call error", "error", err)
 		return err
 	}
 	reply.Data = response
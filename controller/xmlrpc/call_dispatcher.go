@@ -0,0 +1,79 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+)
+
+//dispatchedCall holds a call which is not read from the body of the HTTP request and
+//whose result is returned to the dispatcher instead of being encoded in the HTTP response
+type dispatchedCall struct {
+	request  *ServerRequest
+	response interface{}
+	err      error
+	answered bool
+}
+
+type dispatchedCallKey struct{}
+
+func dispatchedCallFromContext(ctx context.Context) *dispatchedCall {
+	call, _ := ctx.Value(dispatchedCallKey{}).(*dispatchedCall)
+	return call
+}
+
+func (c *dispatchedCall) answer(response interface{}, err error) {
+	c.response, c.err, c.answered = response, err, true
+}
+
+//NewCallDispatcher returns a CallDispatcher serving the calls through the handler of the RPC server the Codec is registered in.
+//The calls reuse the context and the headers of the HTTP request they are dispatched from.
+func NewCallDispatcher(handler http.Handler) controller.CallDispatcher {
+	return func(r *http.Request, methodName string, params []interface{}) (interface{}, error) {
+		call := &dispatchedCall{request: &ServerRequest{MethodName: methodName, Params: params}}
+		callRequest := r.Clone(context.WithValue(r.Context(), dispatchedCallKey{}, call))
+		callRequest.Header.Set("Content-Type", "text/xml")
+		callRequest.Body = http.NoBody
+		callRequest.ContentLength = 0
+		responseWriter := &errorRecorder{header: make(http.Header)}
+
+		handler.ServeHTTP(responseWriter, callRequest)
+
+		if call.answered || call.err != nil {
+			return call.response, call.err
+		}
+		//the call was rejected by the RPC server before reaching the Codec, as it did not match any service
+		fault := controller.FaultMethodNotFound
+		fault.Message += ": " + strings.TrimPrefix(responseWriter.body.String(), "rpc: ")
+		return nil, fault
+	}
+}
+
+//errorRecorder keeps the errors written by the RPC server for the calls it rejects
+type errorRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *errorRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *errorRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *errorRecorder) WriteHeader(status int) {}
+
+//responseData returns the Data field of the reply of the service methods
+func responseData(response interface{}) interface{} {
+	val := reflect.ValueOf(response).Elem()
+	if val.Kind() != reflect.Struct || val.NumField() == 0 {
+		return nil
+	}
+	return val.Field(0).Interface()
+}
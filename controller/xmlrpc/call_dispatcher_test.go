@@ -0,0 +1,121 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/rpc"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+)
+
+type EchoService struct{}
+
+type EchoRequest struct {
+	Message string
+}
+
+func (s *EchoService) Echo(r *http.Request, args *EchoRequest, reply *struct{ Data string }) error {
+	if args.Message == "fault" {
+		return errors.New("call_error")
+	}
+	reply.Data = args.Message
+	return nil
+}
+
+func echoRequestParser(ctx context.Context, request *ServerRequest, output interface{}) error {
+	if len(request.Params) != 1 {
+		return controller.FaultWrongArgumentsNumber
+	}
+	message, ok := request.Params[0].(string)
+	if !ok {
+		return controller.FaultInvalidParams
+	}
+	output.(*EchoRequest).Message = message
+	return nil
+}
+
+func multicallRequestParser(ctx context.Context, request *ServerRequest, output interface{}) error {
+	multicallRequest := output.(*controller.MulticallRequest)
+	for _, call := range request.Params[0].([]interface{}) {
+		callStruct := call.(map[string]interface{})
+		params, _ := callStruct["params"].([]interface{})
+		multicallRequest.Calls = append(multicallRequest.Calls, controller.MulticallCall{MethodName: callStruct["methodName"].(string), Params: params})
+	}
+	return nil
+}
+
+func Test_Multicall(t *testing.T) {
+	const callFormat = `<value><struct><member><name>methodName</name><value><string>%s</string></value></member>` +
+		`<member><name>params</name><value><array><data>%s</data></array></value></member></struct></value>`
+	const faultFormat = `<value><struct><member><name>faultCode</name><value><int>%d</int></value></member>` +
+		`<member><name>faultString</name><value><string>%s</string></value></member></struct></value>`
+
+	tt := []struct {
+		name             string
+		calls            [][2]string
+		expectedResponse []string
+	}{
+		{
+			name: "Multicall success",
+			calls: [][2]string{
+				{"test.echo", "<value><string>first</string></value>"},
+				{"test.echo", "<value><string>second</string></value>"},
+			},
+			expectedResponse: []string{
+				"<value><array><data><value><string>first</string></value></data></array></value>",
+				"<value><array><data><value><string>second</string></value></data></array></value>",
+			},
+		},
+		{
+			name: "Multicall faults",
+			calls: [][2]string{
+				{"test.echo", "<value><string>fault</string></value>"},
+				{"test.echo", "<value><int>1</int></value>"},
+				{"test.unknown", ""},
+				{"system.multicall", "<value><array><data></data></array></value>"},
+				{"test.echo", "<value><string>message</string></value>"},
+			},
+			expectedResponse: []string{
+				strings.Replace(strings.Replace(faultFormat, "%d", "-32500", 1), "%s", "Application Error: call_error", 1),
+				strings.Replace(strings.Replace(faultFormat, "%d", "-32602", 1), "%s", "Invalid Method Parameters", 1),
+				strings.Replace(strings.Replace(faultFormat, "%d", "-32601", 1), "%s", `Method not found: can&#39;t find service &#34;test.unknown&#34;`, 1),
+				strings.Replace(strings.Replace(faultFormat, "%d", "-32600", 1), "%s", "Invalid Request: recursive system.multicall calls are not allowed", 1),
+				"<value><array><data><value><string>message</string></value></data></array></value>",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			codec := NewCodec()
+			codec.RegisterMapping("test.echo", "EchoService.Echo", echoRequestParser)
+			codec.RegisterMapping("system.multicall", "MulticallController.Multicall", multicallRequestParser)
+			rpcServer := rpc.NewServer()
+			rpcServer.RegisterCodec(codec, "text/xml")
+			rpcServer.RegisterService(new(EchoService), "")
+			rpcServer.RegisterService(controller.NewMulticallController(NewCallDispatcher(rpcServer)), "")
+
+			var calls strings.Builder
+			for _, call := range tc.calls {
+				calls.WriteString(strings.Replace(strings.Replace(callFormat, "%s", call[0], 1), "%s", call[1], 1))
+			}
+			body := "<methodCall><methodName>system.multicall</methodName><params><param><value><array><data>" +
+				calls.String() + "</data></array></value></param></params></methodCall>"
+			request := httptest.NewRequest("POST", "/hub/rpc/api", strings.NewReader(body))
+			request.Header.Set("Content-Type", "text/xml")
+			recorder := httptest.NewRecorder()
+
+			rpcServer.ServeHTTP(recorder, request)
+
+			expectedResponse := "<methodResponse><params><param><value><array><data>" +
+				strings.Join(tc.expectedResponse, "") + "</data></array></value></param></params></methodResponse>"
+			if recorder.Body.String() != expectedResponse {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", recorder.Body.String(), expectedResponse)
+			}
+		})
+	}
+}
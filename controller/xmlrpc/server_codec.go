@@ -2,13 +2,15 @@ package xmlrpc
 
 import (
 	"bytes"
-	"fmt"
+	"context"
 	"io/ioutil"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/gorilla/rpc"
 	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/metrics"
 )
 
 // implements a Gorilla XMLRPC Codec, see https://www.gorillatoolkit.org/pkg/rpc#overview
@@ -18,9 +20,10 @@ type Codec struct {
 	defaultMethodByNamespace map[string]string
 	defaultMethod            string
 	parsers                  map[string]Parser
+	descriptions             map[string]controller.MethodDescription
 }
 
-type Parser func(request *ServerRequest, output interface{}) error
+type Parser func(ctx context.Context, request *ServerRequest, output interface{}) error
 
 func NewCodec() *Codec {
 	return &Codec{
@@ -28,6 +31,7 @@ func NewCodec() *Codec {
 		defaultMethodByNamespace: make(map[string]string),
 		defaultMethod:            "",
 		parsers:                  make(map[string]Parser),
+		descriptions:             make(map[string]controller.MethodDescription),
 	}
 }
 
@@ -36,6 +40,27 @@ func (c *Codec) RegisterMapping(mapping string, method string, parser Parser) {
 	c.parsers[c.resolveServiceMethod(method)] = parser
 }
 
+//RegisterDescription documents a mapping registered with RegisterMapping for the introspection methods
+func (c *Codec) RegisterDescription(mapping string, description controller.MethodDescription) {
+	c.descriptions[mapping] = description
+}
+
+//ListMethods returns the sorted methods registered with RegisterMapping
+func (c *Codec) ListMethods() []string {
+	methods := make([]string, 0, len(c.mappings))
+	for method := range c.mappings {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+//DescribeMethod returns the description of a method registered with RegisterMapping, if any
+func (c *Codec) DescribeMethod(method string) (controller.MethodDescription, bool) {
+	description, ok := c.descriptions[method]
+	return description, ok
+}
+
 func (c *Codec) RegisterDefaultMethod(method string, parser Parser) {
 	c.defaultMethod = method
 	c.parsers[c.resolveServiceMethod(method)] = parser
@@ -47,6 +72,9 @@ func (c *Codec) RegisterDefaultMethodForNamespace(namespace, method string, pars
 }
 
 func (c *Codec) NewRequest(r *http.Request) rpc.CodecRequest {
+	if call := dispatchedCallFromContext(r.Context()); call != nil {
+		return c.newCodecRequest(r.Context(), call.request, call)
+	}
 	rawxml, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return &CodecRequest{err: err}
@@ -60,11 +88,32 @@ func (c *Codec) NewRequest(r *http.Request) rpc.CodecRequest {
 		return &CodecRequest{err: err}
 	}
 
+	return c.newCodecRequest(r.Context(), serverRequest, nil)
+}
+
+func (c *Codec) newCodecRequest(ctx context.Context, serverRequest *ServerRequest, call *dispatchedCall) *CodecRequest {
 	userMethod := serverRequest.MethodName
-	serviceMethod := c.resolveServiceMethod(userMethod)
-	parser := c.resolveParser(serviceMethod)
+	serviceMethod, namespace, parser := c.ResolveMethod(userMethod)
+	metrics.SetRequestMethod(ctx, namespace, c.ResolveMethodLabel(userMethod))
+
+	return &CodecRequest{request: serverRequest, serviceMethod: serviceMethod, parser: parser, ctx: ctx, call: call}
+}
+
+//ResolveMethod returns the service method serving the requested method, its namespace and the parser of its arguments,
+//so that the codecs of other protocols can share the registered mappings
+func (c *Codec) ResolveMethod(requestMethod string) (serviceMethod, namespace string, parser Parser) {
+	serviceMethod = c.resolveServiceMethod(requestMethod)
+	return serviceMethod, c.resolveNamespace(requestMethod), c.resolveParser(serviceMethod)
+}
 
-	return &CodecRequest{request: serverRequest, serviceMethod: serviceMethod, parser: parser}
+//ResolveMethodLabel returns the method to report in the metrics. Only the methods registered with RegisterMapping
+//are reported by name, the ones forwarded to the Hub or to the peripheral servers are all reported as "proxied"
+//so that the callers can't make the number of series grow without bounds
+func (c *Codec) ResolveMethodLabel(requestMethod string) string {
+	if _, ok := c.mappings[requestMethod]; ok {
+		return requestMethod
+	}
+	return "proxied"
 }
 
 func (c *Codec) resolveParser(requestMethod string) Parser {
@@ -86,6 +135,17 @@ func (c *Codec) resolveServiceMethod(requestMethod string) string {
 	return requestMethod
 }
 
+//resolveNamespace returns the namespace serving the method, "proxied" for the methods forwarded to the Hub
+func (c *Codec) resolveNamespace(requestMethod string) string {
+	namespace := c.getNamespace(requestMethod)
+	if _, ok := c.mappings[requestMethod]; ok {
+		return namespace
+	} else if _, ok := c.defaultMethodByNamespace[namespace]; ok {
+		return namespace
+	}
+	return "proxied"
+}
+
 func (c *Codec) getNamespace(requestMethod string) string {
 	if len(requestMethod) > 1 {
 		parts := strings.Split(requestMethod, ".")
@@ -104,6 +164,8 @@ type CodecRequest struct {
 	request       *ServerRequest
 	parser        Parser
 	err           error
+	ctx           context.Context
+	call          *dispatchedCall
 }
 
 func (c *CodecRequest) Method() (string, error) {
@@ -115,10 +177,14 @@ func (c *CodecRequest) Method() (string, error) {
 
 func (c *CodecRequest) ReadRequest(args interface{}) error {
 	if c.parser == nil {
-		return controller.FaultInternalError
+		c.err = controller.FaultInternalError
+	} else {
+		c.err = c.parser(c.ctx, c.request, args)
 	}
-	c.err = c.parser(c.request, args)
 	if c.err != nil {
+		if c.call != nil {
+			c.call.err = c.err
+		}
 		return c.err
 	}
 	return nil
@@ -131,19 +197,21 @@ func (c *CodecRequest) WriteResponse(w http.ResponseWriter, response interface{}
 		err = methodErr
 	}
 	if err != nil {
-		var fault controller.FaultError
-
-		switch c.err.(type) {
-		case controller.FaultError:
-			fault = c.err.(controller.FaultError)
-		default:
-			fault = controller.FaultApplicationError
-			fault.Message += fmt.Sprintf(": %v", err)
+		if c.ctx != nil {
+			metrics.SetRequestFault(c.ctx)
+		}
+		fault := controller.ToFaultError(err)
+		if c.call != nil {
+			c.call.answer(nil, fault)
+			return nil
 		}
 		encodedResponse, err = encodeFaultErrorToXML(fault)
 		if err != nil {
 			return err
 		}
+	} else if c.call != nil {
+		c.call.answer(responseData(response), nil)
+		return nil
 	} else {
 		encodedResponse, err = encodeResponseToXML(response)
 		if err != nil {
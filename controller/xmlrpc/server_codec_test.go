@@ -348,3 +348,36 @@ var brokenRequestBodyForMulticastArgs = `
 	</params>
 </methodCall>`
 */
+
+import (
+	"testing"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
+)
+
+func Test_ResolveMethodLabel(t *testing.T) {
+	tt := []struct {
+		name          string
+		method        string
+		expectedLabel string
+	}{
+		{name: "ResolveMethodLabel registered_method", method: "hub.login", expectedLabel: "hub.login"},
+		{name: "ResolveMethodLabel multicast_method", method: "multicast.system.listSystems", expectedLabel: "proxied"},
+		{name: "ResolveMethodLabel unicast_method", method: "unicast.system.listSystems", expectedLabel: "proxied"},
+		{name: "ResolveMethodLabel proxied_method", method: "system.listSystems", expectedLabel: "proxied"},
+	}
+
+	codec := xmlrpc.NewCodec()
+	codec.RegisterMapping("hub.login", "HubLoginController.Login", nil)
+	codec.RegisterDefaultMethodForNamespace("multicast", "MulticastController.Multicast", nil)
+	codec.RegisterDefaultMethodForNamespace("unicast", "UnicastController.Unicast", nil)
+	codec.RegisterDefaultMethod("HubProxyController.ProxyCallToHub", nil)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if label := codec.ResolveMethodLabel(tc.method); label != tc.expectedLabel {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", label, tc.expectedLabel)
+			}
+		})
+	}
+}
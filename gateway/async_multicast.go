@@ -0,0 +1,250 @@
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+const (
+	MulticastJobRunning   = "running"
+	MulticastJobCompleted = "completed"
+	MulticastJobCancelled = "cancelled"
+	MulticastJobFailed    = "failed"
+)
+
+//AsyncMulticaster provides an interface for running multicast calls in the background
+type AsyncMulticaster interface {
+	SubmitMulticastJob(ctx context.Context, hubSessionKey string, call string, serverIDs []int64, argsByServer map[int64][]interface{}, maxConcurrency int, timeout time.Duration) (string, error)
+	GetJobStatus(ctx context.Context, hubSessionKey, jobID string) (*MulticastJobStatus, error)
+	GetJobResult(ctx context.Context, hubSessionKey, jobID string) (*MulticastResponse, error)
+	CancelJob(ctx context.Context, hubSessionKey, jobID string) error
+}
+
+type MulticastJobStatus struct {
+	JobID           string
+	Call            string
+	State           string
+	ServerCount     int
+	SuccessfulCount int
+	FailedCount     int
+	ErrorMessage    string
+}
+
+type asyncMulticaster struct {
+	multicaster          Multicaster
+	hubSessionRepository HubSessionRepository
+	jobRetention         time.Duration
+	mutex                sync.Mutex
+	jobs                 map[string]*multicastJob
+	runningJobs          sync.WaitGroup
+}
+
+//NewAsyncMulticaster instantiates an AsyncMulticaster. Finished jobs are kept for jobRetention
+func NewAsyncMulticaster(multicaster Multicaster, hubSessionRepository HubSessionRepository, jobRetention time.Duration) *asyncMulticaster {
+	return &asyncMulticaster{multicaster: multicaster, hubSessionRepository: hubSessionRepository, jobRetention: jobRetention, jobs: make(map[string]*multicastJob)}
+}
+
+//SubmitMulticastJob starts executing the multicast call in the background and returns the ID of the job.
+//The calls still pending after timeout (if not 0) are aborted.
+func (a *asyncMulticaster) SubmitMulticastJob(ctx context.Context, hubSessionKey string, call string, serverIDs []int64, argsByServer map[int64][]interface{}, maxConcurrency int, timeout time.Duration) (string, error) {
+	if a.hubSessionRepository.RetrieveHubSession(hubSessionKey) == nil {
+		logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+		return "", ErrInvalidHubSessionKey
+	}
+	jobID, err := generateJobID()
+	if err != nil {
+		logging.Error(ctx, "Error ocurred while generating the job ID", "error", err)
+		return "", err
+	}
+	//the job outlives the request submitting it, but keeps its ID for logging
+	jobCtx := logging.WithRequestID(context.Background(), logging.RequestIDFromContext(ctx))
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		jobCtx, cancel = context.WithTimeout(jobCtx, timeout)
+	} else {
+		jobCtx, cancel = context.WithCancel(jobCtx)
+	}
+	job := &multicastJob{
+		id:                  jobID,
+		hubSessionKey:       hubSessionKey,
+		call:                call,
+		serverIDs:           serverIDs,
+		state:               MulticastJobRunning,
+		cancel:              cancel,
+		successfulResponses: make(map[int64]ServerSuccessfulResponse),
+		failedResponses:     make(map[int64]ServerFailedResponse),
+	}
+
+	a.mutex.Lock()
+	a.removeExpiredJobs()
+	a.jobs[jobID] = job
+	a.runningJobs.Add(1)
+	a.mutex.Unlock()
+
+	go func() {
+		defer a.runningJobs.Done()
+		defer cancel()
+		multicastResponse, err := a.multicaster.Multicast(withMulticastProgress(jobCtx, job), hubSessionKey, call, serverIDs, argsByServer, maxConcurrency)
+		job.finish(multicastResponse, err, jobCtx.Err() == context.Canceled)
+	}()
+	return jobID, nil
+}
+
+//GetJobStatus returns the progress of the job
+func (a *asyncMulticaster) GetJobStatus(ctx context.Context, hubSessionKey, jobID string) (*MulticastJobStatus, error) {
+	job, err := a.retrieveJob(ctx, hubSessionKey, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return job.status(), nil
+}
+
+//GetJobResult returns the responses of the servers which already answered, even if the job is still running
+func (a *asyncMulticaster) GetJobResult(ctx context.Context, hubSessionKey, jobID string) (*MulticastResponse, error) {
+	job, err := a.retrieveJob(ctx, hubSessionKey, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return job.result(), nil
+}
+
+//CancelJob aborts the pending server calls of the job. The servers which did not answer yet are reported as failed.
+func (a *asyncMulticaster) CancelJob(ctx context.Context, hubSessionKey, jobID string) error {
+	job, err := a.retrieveJob(ctx, hubSessionKey, jobID)
+	if err != nil {
+		return err
+	}
+	job.cancel()
+	return nil
+}
+
+//WaitForJobs blocks until all the running jobs finish. If ctx is done before, the jobs still running
+//are cancelled and its error is returned once they stop.
+func (a *asyncMulticaster) WaitForJobs(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		a.runningJobs.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+	}
+	a.mutex.Lock()
+	for _, job := range a.jobs {
+		job.cancel()
+	}
+	a.mutex.Unlock()
+	<-finished
+	return ctx.Err()
+}
+
+func (a *asyncMulticaster) retrieveJob(ctx context.Context, hubSessionKey, jobID string) (*multicastJob, error) {
+	if a.hubSessionRepository.RetrieveHubSession(hubSessionKey) == nil {
+		logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+		return nil, ErrInvalidHubSessionKey
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.removeExpiredJobs()
+	job, ok := a.jobs[jobID]
+	if !ok || job.hubSessionKey != hubSessionKey {
+		logging.Error(ctx, "Job was not found", "job_id", jobID)
+		return nil, fmt.Errorf("%w: %v", ErrJobNotFound, jobID)
+	}
+	return job, nil
+}
+
+//removeExpiredJobs must be called holding the mutex
+func (a *asyncMulticaster) removeExpiredJobs() {
+	for jobID, job := range a.jobs {
+		if job.finishedBefore(time.Now().Add(-a.jobRetention)) {
+			delete(a.jobs, jobID)
+		}
+	}
+}
+
+func generateJobID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+type multicastJob struct {
+	id, hubSessionKey, call string
+	serverIDs               []int64
+	cancel                  context.CancelFunc
+
+	mutex               sync.Mutex
+	state               string
+	errorMessage        string
+	finishedAt          time.Time
+	successfulResponses map[int64]ServerSuccessfulResponse
+	failedResponses     map[int64]ServerFailedResponse
+}
+
+func (j *multicastJob) recordSuccessfulResponse(response ServerSuccessfulResponse) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.successfulResponses[response.ServerID] = response
+}
+
+func (j *multicastJob) recordFailedResponse(response ServerFailedResponse) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.failedResponses[response.ServerID] = response
+}
+
+func (j *multicastJob) finish(multicastResponse *MulticastResponse, err error, cancelled bool) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.finishedAt = time.Now()
+	switch {
+	case err != nil:
+		j.state = MulticastJobFailed
+		j.errorMessage = err.Error()
+	case cancelled:
+		j.state = MulticastJobCancelled
+	default:
+		j.state = MulticastJobCompleted
+	}
+	if multicastResponse != nil {
+		j.successfulResponses = multicastResponse.SuccessfulResponses
+		j.failedResponses = multicastResponse.FailedResponses
+	}
+}
+
+func (j *multicastJob) finishedBefore(deadline time.Time) bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.state != MulticastJobRunning && j.finishedAt.Before(deadline)
+}
+
+func (j *multicastJob) status() *MulticastJobStatus {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return &MulticastJobStatus{j.id, j.call, j.state, len(j.serverIDs), len(j.successfulResponses), len(j.failedResponses), j.errorMessage}
+}
+
+func (j *multicastJob) result() *MulticastResponse {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	successfulResponses := make(map[int64]ServerSuccessfulResponse, len(j.successfulResponses))
+	for serverID, response := range j.successfulResponses {
+		successfulResponses[serverID] = response
+	}
+	failedResponses := make(map[int64]ServerFailedResponse, len(j.failedResponses))
+	for serverID, response := range j.failedResponses {
+		failedResponses[serverID] = response
+	}
+	return &MulticastResponse{j.serverIDs, successfulResponses, failedResponses}
+}
@@ -0,0 +1,151 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_AsyncMulticast(t *testing.T) {
+	tt := []struct {
+		name                    string
+		cancel                  bool
+		expectedState           string
+		expectedSuccessfulCount int
+		expectedFailedCount     int
+	}{
+		{name: "AsyncMulticast job_completed", expectedState: MulticastJobCompleted, expectedSuccessfulCount: 3},
+		{name: "AsyncMulticast job_cancelled_pending_calls_should_fail", cancel: true, expectedState: MulticastJobCancelled, expectedSuccessfulCount: 2, expectedFailedCount: 1},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			hubSession := NewHubSession("hubSessionKey", "username", "password", 1)
+			hubSession.ServerSessions[1] = NewServerSession(1, "1-serverEndpoint", "1-sessionKey", "hubSessionKey")
+			hubSession.ServerSessions[2] = NewServerSession(2, "2-serverEndpoint", "2-sessionKey", "hubSessionKey")
+			hubSession.ServerSessions[3] = NewServerSession(3, "3-serverEndpoint", "3-sessionKey", "hubSessionKey")
+
+			mockHubSessionRepository := new(mockHubSessionRepository)
+			mockHubSessionRepository.mockRetrieveHubSession = func(hubSessionKey string) *HubSession {
+				if hubSessionKey == hubSession.HubSessionKey {
+					return hubSession
+				}
+				return nil
+			}
+
+			slowServerReleased := make(chan struct{})
+			mockUyuniCallExecutor := new(mockUyuniCallExecutor)
+			mockUyuniCallExecutor.mockExecuteCall = func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				if endpoint == "2-serverEndpoint" {
+					<-slowServerReleased
+				}
+				return "success_call", nil
+			}
+
+			asyncMulticaster := NewAsyncMulticaster(NewMulticaster(mockUyuniCallExecutor, mockHubSessionRepository, nil, NewServerCallScheduler(0)), mockHubSessionRepository, time.Minute)
+			//servers are called one at a time, so the call to server 3 is still pending while server 2 is answering
+			jobID, err := asyncMulticaster.SubmitMulticastJob(context.Background(), "hubSessionKey", "call", []int64{1, 2, 3}, map[int64][]interface{}{}, 1, 0)
+			if err != nil {
+				t.Fatalf("Unexpected error was returned: %v", err)
+			}
+
+			waitForJob(t, asyncMulticaster, jobID, func(status *MulticastJobStatus) bool { return status.SuccessfulCount == 1 })
+			partialResult, err := asyncMulticaster.GetJobResult(context.Background(), "hubSessionKey", jobID)
+			if err != nil || len(partialResult.SuccessfulResponses) != 1 || partialResult.SuccessfulResponses[1].Response != "success_call" {
+				t.Fatalf("Unexpected partial result: %v, error: %v", partialResult, err)
+			}
+
+			if tc.cancel {
+				if err := asyncMulticaster.CancelJob(context.Background(), "hubSessionKey", jobID); err != nil {
+					t.Fatalf("Unexpected error was returned: %v", err)
+				}
+			}
+			close(slowServerReleased)
+
+			status := waitForJob(t, asyncMulticaster, jobID, func(status *MulticastJobStatus) bool { return status.State != MulticastJobRunning })
+			if status.State != tc.expectedState || status.SuccessfulCount != tc.expectedSuccessfulCount || status.FailedCount != tc.expectedFailedCount {
+				t.Fatalf("expected and actual don't match. Actual was: %v. Expected was: %v, %v successful, %v failed",
+					*status, tc.expectedState, tc.expectedSuccessfulCount, tc.expectedFailedCount)
+			}
+		})
+	}
+}
+
+func Test_AsyncMulticastJobNotFound(t *testing.T) {
+	hubSession := NewHubSession("hubSessionKey", "username", "password", 1)
+	mockHubSessionRepository := new(mockHubSessionRepository)
+	mockHubSessionRepository.mockRetrieveHubSession = func(hubSessionKey string) *HubSession { return hubSession }
+
+	asyncMulticaster := NewAsyncMulticaster(NewMulticaster(new(mockUyuniCallExecutor), mockHubSessionRepository, nil, NewServerCallScheduler(0)), mockHubSessionRepository, time.Minute)
+
+	if _, err := asyncMulticaster.GetJobStatus(context.Background(), "hubSessionKey", "unknownJobID"); err == nil || err.Error() != "Job not found: unknownJobID" {
+		t.Fatalf("expected and actual don't match. Actual was: %v. Expected was: %v", err, "Job not found: unknownJobID")
+	}
+}
+
+func Test_WaitForJobs(t *testing.T) {
+	tt := []struct {
+		name          string
+		slowServer    bool
+		expectedErr   error
+		expectedState string
+	}{
+		{name: "WaitForJobs jobs_finished", expectedState: MulticastJobCompleted},
+		{name: "WaitForJobs deadline_exceeded_jobs_cancelled", slowServer: true, expectedErr: context.DeadlineExceeded, expectedState: MulticastJobCancelled},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			hubSession := NewHubSession("hubSessionKey", "username", "password", 1)
+			hubSession.ServerSessions[1] = NewServerSession(1, "1-serverEndpoint", "1-sessionKey", "hubSessionKey")
+			hubSession.ServerSessions[2] = NewServerSession(2, "2-serverEndpoint", "2-sessionKey", "hubSessionKey")
+			mockHubSessionRepository := new(mockHubSessionRepository)
+			mockHubSessionRepository.mockRetrieveHubSession = func(hubSessionKey string) *HubSession { return hubSession }
+
+			slowServerReleased := make(chan struct{})
+			mockUyuniCallExecutor := new(mockUyuniCallExecutor)
+			mockUyuniCallExecutor.mockExecuteCall = func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				if tc.slowServer && endpoint == "1-serverEndpoint" {
+					<-slowServerReleased
+				}
+				return "success_call", nil
+			}
+
+			asyncMulticaster := NewAsyncMulticaster(NewMulticaster(mockUyuniCallExecutor, mockHubSessionRepository, nil, NewServerCallScheduler(0)), mockHubSessionRepository, time.Minute)
+			jobID, err := asyncMulticaster.SubmitMulticastJob(context.Background(), "hubSessionKey", "call", []int64{1, 2}, map[int64][]interface{}{}, 1, 0)
+			if err != nil {
+				t.Fatalf("Unexpected error was returned: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			//the slow server answers after the deadline, when the call to the other server was already cancelled
+			time.AfterFunc(100*time.Millisecond, func() { close(slowServerReleased) })
+			err = asyncMulticaster.WaitForJobs(ctx)
+
+			if err != tc.expectedErr {
+				t.Fatalf("expected and actual don't match. Actual was: %v. Expected was: %v", err, tc.expectedErr)
+			}
+			status, _ := asyncMulticaster.GetJobStatus(context.Background(), "hubSessionKey", jobID)
+			if status.State != tc.expectedState {
+				t.Fatalf("expected and actual don't match. Actual was: %v. Expected was: %v", status.State, tc.expectedState)
+			}
+		})
+	}
+}
+
+func waitForJob(t *testing.T, asyncMulticaster AsyncMulticaster, jobID string, condition func(status *MulticastJobStatus) bool) *MulticastJobStatus {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := asyncMulticaster.GetJobStatus(context.Background(), "hubSessionKey", jobID)
+		if err != nil {
+			t.Fatalf("Unexpected error was returned: %v", err)
+		}
+		if condition(status) {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %v did not reach the expected status", jobID)
+	return nil
+}
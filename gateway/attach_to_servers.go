@@ -1,14 +1,25 @@
 package gateway
 
 import (
+	"context"
 	"errors"
-	"log"
+	"fmt"
+	"sort"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
 type ServerAuthenticator interface {
-	AttachToServers(hubSessionKey string, serverIDs []int64, credentialsByServer map[int64]*Credentials) (*MulticastResponse, error)
+	AttachToServers(ctx context.Context, hubSessionKey string, serverIDs []int64, credentialsByServer map[int64]*Credentials) (*MulticastResponse, error)
+	DetachFromServers(ctx context.Context, hubSessionKey string, serverIDs []int64) (*MulticastResponse, error)
+	RetryFailedAttachments(ctx context.Context, hubSessionKey string) (*MulticastResponse, error)
 }
 
+var (
+	errServerNotInHubSession    = fmt.Errorf("%w: server is not part of the hub session", ErrServerNotAttached)
+	errRetryNeedsRelayLoginMode = errors.New("Failed attachments can only be retried in relay or autoconnect mode, use hub.attachToServers instead")
+)
+
 type Credentials struct {
 	Username, Password string
 }
@@ -19,63 +30,124 @@ type serverAuthenticator struct {
 	uyuniTopologyInfoRetriever UyuniTopologyInfoRetriever
 	hubSessionRepository       HubSessionRepository
 	serverSessionRepository    ServerSessionRepository
+	serverCallScheduler        ServerCallScheduler
 }
 
 func NewServerAuthenticator(hubAPIEndpoint string, uyuniAuthenticator UyuniAuthenticator,
 	uyuniTopologyInfoRetriever UyuniTopologyInfoRetriever, hubSessionRepository HubSessionRepository,
-	serverSessionRepository ServerSessionRepository) *serverAuthenticator {
-	return &serverAuthenticator{hubAPIEndpoint, uyuniAuthenticator, uyuniTopologyInfoRetriever, hubSessionRepository, serverSessionRepository}
+	serverSessionRepository ServerSessionRepository, serverCallScheduler ServerCallScheduler) *serverAuthenticator {
+	return &serverAuthenticator{hubAPIEndpoint, uyuniAuthenticator, uyuniTopologyInfoRetriever, hubSessionRepository, serverSessionRepository, serverCallScheduler}
 }
 
-func (a *serverAuthenticator) AttachToServers(hubSessionKey string, serverIDs []int64, credentialsByServer map[int64]*Credentials) (*MulticastResponse, error) {
+func (a *serverAuthenticator) AttachToServers(ctx context.Context, hubSessionKey string, serverIDs []int64, credentialsByServer map[int64]*Credentials) (*MulticastResponse, error) {
 	hubSession := a.hubSessionRepository.RetrieveHubSession(hubSessionKey)
 	if hubSession == nil {
-		log.Printf("HubSession was not found: %v", hubSessionKey)
-		return nil, errors.New("Authentication error: provided session key is invalid")
+		logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+		return nil, ErrInvalidHubSessionKey
 	}
-	if hubSession.loginMode == relayLoginMode {
+	if hubSession.relaysCredentials() {
 		credentialsByServer = generateSameCredentialsForServers(serverIDs, hubSession.username, hubSession.password)
 	}
-	return a.attachServersToHubSession(serverIDs, credentialsByServer, hubSessionKey)
+	return a.attachServersToHubSession(ctx, serverIDs, credentialsByServer, hubSessionKey)
+}
+
+//DetachFromServers logs out from the given servers in parallel and removes them from the hub session.
+//The servers are removed even if logging out fails, as their sessions will expire anyway.
+//The servers which were not attached to the hub session are reported as failed.
+func (a *serverAuthenticator) DetachFromServers(ctx context.Context, hubSessionKey string, serverIDs []int64) (*MulticastResponse, error) {
+	hubSession := a.hubSessionRepository.RetrieveHubSession(hubSessionKey)
+	if hubSession == nil {
+		logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+		return nil, ErrInvalidHubSessionKey
+	}
+	serverSessions := make(map[int64]*ServerSession, len(serverIDs))
+	notAttachedServerIDs := make([]int64, 0)
+	for _, serverID := range serverIDs {
+		if serverSession := hubSession.ServerSession(serverID); serverSession != nil {
+			serverSessions[serverID] = serverSession
+		} else {
+			notAttachedServerIDs = append(notAttachedServerIDs, serverID)
+		}
+	}
+
+	logoutResponse := logoutFromServers(ctx, a.serverCallScheduler, a.uyuniAuthenticator, serverSessions)
+	for _, serverID := range notAttachedServerIDs {
+		logoutResponse.FailedResponses[serverID] = ServerFailedResponse{serverID, "", errServerNotInHubSession.Error(), 0, errServerNotInHubSession, 0}
+	}
+	logoutResponse.ServerIDs = serverIDs
+
+	detachedServerIDs := make([]int64, 0, len(serverSessions))
+	for serverID := range serverSessions {
+		detachedServerIDs = append(detachedServerIDs, serverID)
+	}
+	a.serverSessionRepository.RemoveServerSessions(hubSessionKey, detachedServerIDs)
+	return logoutResponse, nil
+}
+
+//RetryFailedAttachments attaches again to the servers the hub session failed to attach to, leaving the attached ones untouched.
+//The credentials of the hub session are reused, so it is only supported in relay and autoconnect modes.
+func (a *serverAuthenticator) RetryFailedAttachments(ctx context.Context, hubSessionKey string) (*MulticastResponse, error) {
+	hubSession := a.hubSessionRepository.RetrieveHubSession(hubSessionKey)
+	if hubSession == nil {
+		logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+		return nil, ErrInvalidHubSessionKey
+	}
+	if !hubSession.relaysCredentials() {
+		return nil, errRetryNeedsRelayLoginMode
+	}
+	failedServerIDs := make([]int64, 0)
+	for serverID, serverSession := range hubSession.CopyServerSessions() {
+		if !serverSession.IsAttached() {
+			failedServerIDs = append(failedServerIDs, serverID)
+		}
+	}
+	if len(failedServerIDs) == 0 {
+		return &MulticastResponse{failedServerIDs, make(map[int64]ServerSuccessfulResponse), make(map[int64]ServerFailedResponse)}, nil
+	}
+	sort.Slice(failedServerIDs, func(i, j int) bool { return failedServerIDs[i] < failedServerIDs[j] })
+	credentialsByServer := generateSameCredentialsForServers(failedServerIDs, hubSession.username, hubSession.password)
+	return a.attachServersToHubSession(ctx, failedServerIDs, credentialsByServer, hubSessionKey)
 }
 
-func (a *serverAuthenticator) attachServersToHubSession(serverIDs []int64, credentialsByServer map[int64]*Credentials, hubSessionKey string) (*MulticastResponse, error) {
-	retrieveServerAPIResponse, err := a.uyuniTopologyInfoRetriever.RetrieveServerAPIEndpoints(a.hubAPIEndpoint, hubSessionKey, serverIDs)
+func (a *serverAuthenticator) attachServersToHubSession(ctx context.Context, serverIDs []int64, credentialsByServer map[int64]*Credentials, hubSessionKey string) (*MulticastResponse, error) {
+	retrieveServerAPIResponse, err := a.uyuniTopologyInfoRetriever.RetrieveServerAPIEndpoints(ctx, a.hubAPIEndpoint, hubSessionKey, serverIDs)
 	if err != nil {
 		return nil, err
 	}
 	multicastCallRequest := a.generateLoginMuticastCallRequest(credentialsByServer, retrieveServerAPIResponse.SuccessfulResponses)
-	loginResponse := executeCallOnServers(multicastCallRequest)
+	loginResponse := executeCallOnServers(ctx, a.serverCallScheduler, multicastCallRequest)
 
 	failedResponses := loginResponse.FailedResponses
+	//the endpoints of the servers which could not be looked up are unknown
 	for serverID, errorMessage := range retrieveServerAPIResponse.FailedResponses {
-		failedResponses[serverID] = ServerFailedResponse{serverID, a.hubAPIEndpoint, errorMessage}
+		failedResponses[serverID] = ServerFailedResponse{serverID, "", errorMessage, 0, errors.New(errorMessage), 0}
 	}
 	loginResponse.FailedResponses = failedResponses
+	loginResponse.ServerIDs = serverIDs
 	a.saveServerSessions(hubSessionKey, loginResponse)
 	return loginResponse, nil
 }
 
 func (a *serverAuthenticator) generateLoginMuticastCallRequest(credentialsByServer map[int64]*Credentials, endpointByServer map[int64]string) *multicastCallRequest {
-	call := func(endpoint string, args []interface{}) (interface{}, error) {
-		return a.uyuniAuthenticator.Login(endpoint, args[0].(string), args[1].(string))
+	call := func(ctx context.Context, serverID int64, endpoint string, args []interface{}) (interface{}, error) {
+		return a.uyuniAuthenticator.Login(ctx, endpoint, args[0].(string), args[1].(string))
 	}
 	serverCallInfos := make([]serverCallInfo, 0, len(credentialsByServer))
 	for serverID, endpoint := range endpointByServer {
 		args := []interface{}{credentialsByServer[serverID].Username, credentialsByServer[serverID].Password}
 		serverCallInfos = append(serverCallInfos, serverCallInfo{serverID, endpoint, args})
 	}
-	return &multicastCallRequest{call, serverCallInfos}
+	return &multicastCallRequest{call, serverCallInfos, 0}
 }
 
 func (a *serverAuthenticator) saveServerSessions(hubSessionKey string, loginResponses *MulticastResponse) {
 	serverSessions := make(map[int64]*ServerSession)
 	for serverID, response := range loginResponses.SuccessfulResponses {
-		serverSessions[serverID] = &ServerSession{serverID, response.endpoint, response.Response.(string), hubSessionKey}
+		serverSessions[serverID] = NewServerSession(serverID, response.endpoint, response.Response.(string), hubSessionKey)
 	}
-	//save for failed as well
+	//the failed ones are saved as well, so that the calls to them are rejected with the reason why attaching failed
 	for serverID, response := range loginResponses.FailedResponses {
-		serverSessions[serverID] = &ServerSession{serverID, response.endpoint, "login-error", hubSessionKey}
+		serverSessions[serverID] = NewFailedServerSession(serverID, response.endpoint, response.ErrorMessage, hubSessionKey)
 	}
 	a.serverSessionRepository.SaveServerSessions(hubSessionKey, serverSessions)
 }
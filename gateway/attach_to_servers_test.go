@@ -0,0 +1,259 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func Test_DetachFromServers(t *testing.T) {
+	tt := []struct {
+		name                      string
+		hubSessionKey             string
+		serverIDs                 []int64
+		expectedMulticastResponse *MulticastResponse
+		expectedRemovedServerIDs  []int64
+		expectedErr               string
+	}{
+		{
+			name:          "DetachFromServers partial_failure",
+			hubSessionKey: "hubSessionKey",
+			serverIDs:     []int64{1, 2, 3, 4},
+			expectedMulticastResponse: &MulticastResponse{
+				[]int64{1, 2, 3, 4},
+				map[int64]ServerSuccessfulResponse{
+					1: ServerSuccessfulResponse{1, "1-serverEndpoint", nil, 1},
+					4: ServerSuccessfulResponse{4, "4-serverEndpoint", nil, 0},
+				},
+				map[int64]ServerFailedResponse{
+					2: ServerFailedResponse{2, "2-serverEndpoint", "logout_error", 1, errors.New("logout_error"), 0},
+					3: ServerFailedResponse{3, "", errServerNotInHubSession.Error(), 0, errServerNotInHubSession, 0},
+				},
+			},
+			expectedRemovedServerIDs: []int64{1, 2, 4},
+		},
+		{
+			name:          "DetachFromServers invalid_hub_session_key",
+			hubSessionKey: "invalidHubSessionKey",
+			serverIDs:     []int64{1},
+			expectedErr:   "Authentication error: provided session key is invalid",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			hubSession := NewHubSession("hubSessionKey", "username", "password", 1)
+			hubSession.ServerSessions[1] = NewServerSession(1, "1-serverEndpoint", "1-sessionKey", "hubSessionKey")
+			hubSession.ServerSessions[2] = NewServerSession(2, "2-serverEndpoint", "2-sessionKey", "hubSessionKey")
+			hubSession.ServerSessions[4] = NewFailedServerSession(4, "4-serverEndpoint", "login_error", "hubSessionKey")
+
+			mockHubSessionRepository := new(mockHubSessionRepository)
+			mockHubSessionRepository.mockRetrieveHubSession = func(hubSessionKey string) *HubSession {
+				if hubSessionKey == hubSession.HubSessionKey {
+					return hubSession
+				}
+				return nil
+			}
+			var removedServerIDs []int64
+			mockServerSessionRepository := new(mockServerSessionRepository)
+			mockServerSessionRepository.mockRemoveServerSessions = func(hubSessionKey string, serverIDs []int64) {
+				removedServerIDs = serverIDs
+			}
+			mockUyuniAuthenticator := new(mockUyuniAuthenticator)
+			mockUyuniAuthenticator.mockLogout = func(endpoint, sessionKey string) error {
+				switch endpoint {
+				case "2-serverEndpoint":
+					return errors.New("logout_error")
+				case "4-serverEndpoint":
+					return errors.New("unexpected_logout")
+				}
+				return nil
+			}
+
+			serverAuthenticator := NewServerAuthenticator("hubAPIEndpoint", mockUyuniAuthenticator, nil, mockHubSessionRepository, mockServerSessionRepository, NewServerCallScheduler(0))
+			multicastResponse, err := serverAuthenticator.DetachFromServers(context.Background(), tc.hubSessionKey, tc.serverIDs)
+
+			if err != nil && tc.expectedErr != err.Error() {
+				t.Fatalf("Error during executing request: %v", err)
+			}
+			if err == nil && !reflect.DeepEqual(withoutElapsedTimes(multicastResponse), tc.expectedMulticastResponse) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", multicastResponse, tc.expectedMulticastResponse)
+			}
+			sort.Slice(removedServerIDs, func(i, j int) bool { return removedServerIDs[i] < removedServerIDs[j] })
+			if !reflect.DeepEqual(removedServerIDs, tc.expectedRemovedServerIDs) {
+				t.Fatalf("expected and actual removed servers don't match. Actual was:  %v. Expected was: %v", removedServerIDs, tc.expectedRemovedServerIDs)
+			}
+		})
+	}
+}
+
+func Test_DetachFromServersWhileMulticasting(t *testing.T) {
+	hubSession := NewHubSession("hubSessionKey", "username", "password", manualLoginMode)
+	serverIDs := make([]int64, 0)
+	for serverID := int64(1); serverID <= 10; serverID++ {
+		hubSession.ServerSessions[serverID] = NewServerSession(serverID, strconv.FormatInt(serverID, 10)+"-serverEndpoint", "sessionKey", "hubSessionKey")
+		serverIDs = append(serverIDs, serverID)
+	}
+
+	//the repositories are backed by the hub session, as the ones of the session package
+	mockHubSessionRepository := new(mockHubSessionRepository)
+	mockHubSessionRepository.mockRetrieveHubSession = func(hubSessionKey string) *HubSession {
+		return hubSession
+	}
+	mockServerSessionRepository := new(mockServerSessionRepository)
+	mockServerSessionRepository.mockRemoveServerSessions = func(hubSessionKey string, serverIDs []int64) {
+		hubSession.RemoveServerSessions(serverIDs)
+	}
+	mockUyuniAuthenticator := new(mockUyuniAuthenticator)
+	mockUyuniAuthenticator.mockLogout = func(endpoint, sessionKey string) error {
+		return nil
+	}
+	mockUyuniCallExecutor := new(mockUyuniCallExecutor)
+	mockUyuniCallExecutor.mockExecuteCall = func(endpoint string, call string, args []interface{}) (interface{}, error) {
+		return "success_call", nil
+	}
+
+	serverAuthenticator := NewServerAuthenticator("hubAPIEndpoint", mockUyuniAuthenticator, nil, mockHubSessionRepository, mockServerSessionRepository, NewServerCallScheduler(0))
+	multicaster := NewMulticaster(mockUyuniCallExecutor, mockHubSessionRepository, nil, NewServerCallScheduler(0))
+
+	//the multicasts fail once their servers are detached, only the access to the server sessions matters here
+	var wg sync.WaitGroup
+	for _, serverID := range serverIDs {
+		wg.Add(2)
+		go func(serverID int64) {
+			defer wg.Done()
+			serverAuthenticator.DetachFromServers(context.Background(), "hubSessionKey", []int64{serverID})
+		}(serverID)
+		go func() {
+			defer wg.Done()
+			multicaster.Multicast(context.Background(), "hubSessionKey", "call", serverIDs, map[int64][]interface{}{}, 0)
+		}()
+	}
+	wg.Wait()
+
+	if serverSessions := hubSession.CopyServerSessions(); len(serverSessions) != 0 {
+		t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", serverSessions, map[int64]*ServerSession{})
+	}
+}
+
+func Test_RetryFailedAttachments(t *testing.T) {
+	tt := []struct {
+		name                      string
+		hubSessionKey             string
+		loginMode                 int
+		failedServerIDs           []int64
+		expectedMulticastResponse *MulticastResponse
+		expectedSavedStates       map[int64]string
+		expectedErr               string
+	}{
+		{
+			name:            "RetryFailedAttachments partial_failure",
+			hubSessionKey:   "hubSessionKey",
+			loginMode:       relayLoginMode,
+			failedServerIDs: []int64{3, 2},
+			expectedMulticastResponse: &MulticastResponse{
+				[]int64{2, 3},
+				map[int64]ServerSuccessfulResponse{
+					2: ServerSuccessfulResponse{2, "2-serverEndpoint", "2-sessionKey", 1},
+				},
+				map[int64]ServerFailedResponse{
+					3: ServerFailedResponse{3, "3-serverEndpoint", "login_error", 1, errors.New("login_error"), 0},
+				},
+			},
+			expectedSavedStates: map[int64]string{2: ServerAttachmentStateAttached, 3: ServerAttachmentStateFailed},
+		},
+		{
+			name:            "RetryFailedAttachments endpoint_lookup_failure",
+			hubSessionKey:   "hubSessionKey",
+			loginMode:       autoconnectLoginMode,
+			failedServerIDs: []int64{4},
+			expectedMulticastResponse: &MulticastResponse{
+				[]int64{4},
+				map[int64]ServerSuccessfulResponse{},
+				map[int64]ServerFailedResponse{
+					4: ServerFailedResponse{4, "", "endpoint_error", 0, errors.New("endpoint_error"), 0},
+				},
+			},
+			expectedSavedStates: map[int64]string{4: ServerAttachmentStateFailed},
+		},
+		{
+			name:                      "RetryFailedAttachments no_failed_servers",
+			hubSessionKey:             "hubSessionKey",
+			loginMode:                 relayLoginMode,
+			expectedMulticastResponse: &MulticastResponse{[]int64{}, map[int64]ServerSuccessfulResponse{}, map[int64]ServerFailedResponse{}},
+		},
+		{
+			name:            "RetryFailedAttachments manual_login_mode",
+			hubSessionKey:   "hubSessionKey",
+			loginMode:       manualLoginMode,
+			failedServerIDs: []int64{2},
+			expectedErr:     errRetryNeedsRelayLoginMode.Error(),
+		},
+		{
+			name:          "RetryFailedAttachments invalid_hub_session_key",
+			hubSessionKey: "invalidHubSessionKey",
+			expectedErr:   "Authentication error: provided session key is invalid",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			hubSession := NewHubSession("hubSessionKey", "username", "password", tc.loginMode)
+			hubSession.ServerSessions[1] = NewServerSession(1, "1-serverEndpoint", "1-sessionKey", "hubSessionKey")
+			for _, serverID := range tc.failedServerIDs {
+				hubSession.ServerSessions[serverID] = NewFailedServerSession(serverID, "", "previous_error", "hubSessionKey")
+			}
+
+			mockHubSessionRepository := new(mockHubSessionRepository)
+			mockHubSessionRepository.mockRetrieveHubSession = func(hubSessionKey string) *HubSession {
+				if hubSessionKey == hubSession.HubSessionKey {
+					return hubSession
+				}
+				return nil
+			}
+			savedStates := make(map[int64]string)
+			mockServerSessionRepository := new(mockServerSessionRepository)
+			mockServerSessionRepository.mockSaveServerSessions = func(hubSessionKey string, serverSessions map[int64]*ServerSession) {
+				for serverID, serverSession := range serverSessions {
+					savedStates[serverID] = serverSession.AttachmentState()
+				}
+			}
+			mockUyuniTopologyInfoRetriever := new(mockUyuniTopologyInfoRetriever)
+			mockUyuniTopologyInfoRetriever.mockRetrieveServerAPIEndpoints = func(endpoint, sessionKey string, serverIDs []int64) (*RetrieveServerAPIEndpointsResponse, error) {
+				endpoints, failedResponses := make(map[int64]string), make(map[int64]string)
+				for _, serverID := range serverIDs {
+					if serverID == 4 {
+						failedResponses[serverID] = "endpoint_error"
+						continue
+					}
+					endpoints[serverID] = strconv.FormatInt(serverID, 10) + "-serverEndpoint"
+				}
+				return &RetrieveServerAPIEndpointsResponse{endpoints, failedResponses}, nil
+			}
+			mockUyuniAuthenticator := new(mockUyuniAuthenticator)
+			mockUyuniAuthenticator.mockLogin = func(endpoint, username, password string) (string, error) {
+				if endpoint == "3-serverEndpoint" {
+					return "", errors.New("login_error")
+				}
+				return "2-sessionKey", nil
+			}
+
+			serverAuthenticator := NewServerAuthenticator("hubAPIEndpoint", mockUyuniAuthenticator, mockUyuniTopologyInfoRetriever, mockHubSessionRepository, mockServerSessionRepository, NewServerCallScheduler(0))
+			multicastResponse, err := serverAuthenticator.RetryFailedAttachments(context.Background(), tc.hubSessionKey)
+
+			if err != nil && tc.expectedErr != err.Error() {
+				t.Fatalf("Error during executing request: %v", err)
+			}
+			if err == nil && !reflect.DeepEqual(withoutElapsedTimes(multicastResponse), tc.expectedMulticastResponse) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", multicastResponse, tc.expectedMulticastResponse)
+			}
+			if len(tc.expectedSavedStates) > 0 && !reflect.DeepEqual(savedStates, tc.expectedSavedStates) {
+				t.Fatalf("expected and actual saved states don't match. Actual was:  %v. Expected was: %v", savedStates, tc.expectedSavedStates)
+			}
+		})
+	}
+}
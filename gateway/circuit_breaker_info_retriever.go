@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"context"
+	"sort"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+//CircuitBreakerInfoRetriever provides an interface for retrieving the state of the circuit breakers
+//of the servers attached to a hub session
+type CircuitBreakerInfoRetriever interface {
+	ListServerCircuitBreakers(ctx context.Context, hubSessionKey string) ([]ServerCircuitBreakerState, error)
+}
+
+type ServerCircuitBreakerState struct {
+	ServerID            int64
+	Endpoint            string
+	State               string
+	ConsecutiveFailures int
+}
+
+type circuitBreakerInfoRetriever struct {
+	uyuniCircuitBreakerStateRetriever UyuniCircuitBreakerStateRetriever
+	hubSessionRepository              HubSessionRepository
+}
+
+//NewCircuitBreakerInfoRetriever instantiates a CircuitBreakerInfoRetriever
+func NewCircuitBreakerInfoRetriever(uyuniCircuitBreakerStateRetriever UyuniCircuitBreakerStateRetriever, hubSessionRepository HubSessionRepository) *circuitBreakerInfoRetriever {
+	return &circuitBreakerInfoRetriever{uyuniCircuitBreakerStateRetriever, hubSessionRepository}
+}
+
+//ListServerCircuitBreakers returns the circuit breaker state of every server attached to the hub session, sorted by server ID
+func (r *circuitBreakerInfoRetriever) ListServerCircuitBreakers(ctx context.Context, hubSessionKey string) ([]ServerCircuitBreakerState, error) {
+	hubSession := r.hubSessionRepository.RetrieveHubSession(hubSessionKey)
+	if hubSession == nil {
+		logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+		return nil, ErrInvalidHubSessionKey
+	}
+	serverSessions := hubSession.CopyServerSessions()
+	states := make([]ServerCircuitBreakerState, 0, len(serverSessions))
+	for serverID, serverSession := range serverSessions {
+		state := r.uyuniCircuitBreakerStateRetriever.RetrieveCircuitBreakerState(serverSession.serverAPIEndpoint)
+		states = append(states, ServerCircuitBreakerState{serverID, serverSession.serverAPIEndpoint, state.State, state.ConsecutiveFailures})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ServerID < states[j].ServerID })
+	return states, nil
+}
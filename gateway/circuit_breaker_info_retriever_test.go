@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func Test_ListServerCircuitBreakers(t *testing.T) {
+	hubSession := NewHubSession("hubSessionKey", "username", "password", 1)
+	hubSession.ServerSessions[2] = NewServerSession(2, "2-serverEndpoint", "2-sessionKey", "hubSessionKey")
+	hubSession.ServerSessions[1] = NewServerSession(1, "1-serverEndpoint", "1-sessionKey", "hubSessionKey")
+
+	tt := []struct {
+		name                  string
+		hubSessionKey         string
+		expectedBreakerStates []ServerCircuitBreakerState
+		expectedErr           string
+	}{
+		{
+			name:          "ListServerCircuitBreakers states_of_attached_servers",
+			hubSessionKey: "hubSessionKey",
+			expectedBreakerStates: []ServerCircuitBreakerState{
+				{1, "1-serverEndpoint", CircuitBreakerClosed, 0},
+				{2, "2-serverEndpoint", CircuitBreakerOpen, 5},
+			},
+		},
+		{
+			name:          "ListServerCircuitBreakers invalid_hub_session_key",
+			hubSessionKey: "invalidHubSessionKey",
+			expectedErr:   "Authentication error: provided session key is invalid",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mockHubSessionRepository := new(mockHubSessionRepository)
+			mockHubSessionRepository.mockRetrieveHubSession = func(hubSessionKey string) *HubSession {
+				if hubSessionKey == hubSession.HubSessionKey {
+					return hubSession
+				}
+				return nil
+			}
+			mockUyuniCircuitBreakerStateRetriever := new(mockUyuniCircuitBreakerStateRetriever)
+			mockUyuniCircuitBreakerStateRetriever.mockRetrieveCircuitBreakerState = func(endpoint string) *CircuitBreakerState {
+				if endpoint == "2-serverEndpoint" {
+					return &CircuitBreakerState{CircuitBreakerOpen, 5}
+				}
+				return &CircuitBreakerState{CircuitBreakerClosed, 0}
+			}
+
+			circuitBreakerInfoRetriever := NewCircuitBreakerInfoRetriever(mockUyuniCircuitBreakerStateRetriever, mockHubSessionRepository)
+			breakerStates, err := circuitBreakerInfoRetriever.ListServerCircuitBreakers(context.Background(), tc.hubSessionKey)
+
+			if err != nil && tc.expectedErr != err.Error() {
+				t.Fatalf("Error during executing request: %v", err)
+			}
+			if err == nil && !reflect.DeepEqual(breakerStates, tc.expectedBreakerStates) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", breakerStates, tc.expectedBreakerStates)
+			}
+		})
+	}
+}
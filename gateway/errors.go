@@ -0,0 +1,11 @@
+package gateway
+
+import "errors"
+
+//Errors generated by the gateway, which are reported to the callers with their own fault codes
+var (
+	ErrInvalidHubSessionKey = errors.New("Authentication error: provided session key is invalid")
+	ErrJobNotFound          = errors.New("Job not found")
+	ErrServerUnavailable    = errors.New("Server unavailable")
+	ErrServerNotAttached    = errors.New("Server not attached")
+)
@@ -1,9 +1,13 @@
 package gateway
 
-import "log"
+import (
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
 
 type HubProxy interface {
-	ProxyCallToHub(call string, args []interface{}) (interface{}, error)
+	ProxyCallToHub(ctx context.Context, call string, args []interface{}) (interface{}, error)
 }
 
 type hubProxy struct {
@@ -15,10 +19,10 @@ func NewHubProxy(hubAPIEndpoint string, uyuniCallExecutor UyuniCallExecutor) *hu
 	return &hubProxy{hubAPIEndpoint, uyuniCallExecutor}
 }
 
-func (p *hubProxy) ProxyCallToHub(call string, args []interface{}) (interface{}, error) {
-	response, err := p.uyuniCallExecutor.ExecuteCall(p.hubAPIEndpoint, call, args)
+func (p *hubProxy) ProxyCallToHub(ctx context.Context, call string, args []interface{}) (interface{}, error) {
+	response, err := p.uyuniCallExecutor.ExecuteCall(ctx, p.hubAPIEndpoint, call, args)
 	if err != nil {
-		log.Printf("Error ocurred when delegating call to Hub: %v", err)
+		logging.Error(ctx, "Error ocurred when delegating call to Hub", "error", err)
 		return nil, err
 	}
 	return response, nil
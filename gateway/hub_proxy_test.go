@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
@@ -39,7 +40,7 @@ func Test_ProxyCallToHub(t *testing.T) {
 
 			hubProxy := NewHubProxy("hub_API_endpoint", mockUyuniCallExecutor)
 
-			response, err := hubProxy.ProxyCallToHub("call", tc.args)
+			response, err := hubProxy.ProxyCallToHub(context.Background(), "call", tc.args)
 
 			if err != nil && tc.expectedErr != err.Error() {
 				t.Fatalf("Error during executing request: %v", err)
@@ -1,19 +1,22 @@
 package gateway
 
 import (
-	"log"
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
 const (
-	manualLoginMode = iota // 0
-	relayLoginMode         // 1
+	manualLoginMode      = iota // 0
+	relayLoginMode              // 1
+	autoconnectLoginMode        // 2
 )
 
 //HubLoginer interface for Login operations
 type HubLoginer interface {
-	Login(username, password string) (string, error)
-	LoginWithAuthRelayMode(username, password string) (string, error)
-	LoginWithAutoconnectMode(username, password string) (*LoginWithAutoconnectModeResponse, error)
+	Login(ctx context.Context, username, password string) (string, error)
+	LoginWithAuthRelayMode(ctx context.Context, username, password string) (string, error)
+	LoginWithAutoconnectMode(ctx context.Context, username, password string) (*LoginWithAutoconnectModeResponse, error)
 }
 
 type hubLoginer struct {
@@ -31,12 +34,12 @@ func NewHubLoginer(hubAPIEndpoint string, uyuniAuthenticator UyuniAuthenticator,
 	return &hubLoginer{hubAPIEndpoint, uyuniAuthenticator, serverAuthenticator, uyuniTopologyInfoRetriever, hubSessionRepository}
 }
 
-func (h *hubLoginer) Login(username, password string) (string, error) {
-	return h.loginToHub(username, password, manualLoginMode)
+func (h *hubLoginer) Login(ctx context.Context, username, password string) (string, error) {
+	return h.loginToHub(ctx, username, password, manualLoginMode)
 }
 
-func (h *hubLoginer) LoginWithAuthRelayMode(username, password string) (string, error) {
-	return h.loginToHub(username, password, relayLoginMode)
+func (h *hubLoginer) LoginWithAuthRelayMode(ctx context.Context, username, password string) (string, error) {
+	return h.loginToHub(ctx, username, password, relayLoginMode)
 }
 
 type LoginWithAutoconnectModeResponse struct {
@@ -44,26 +47,26 @@ type LoginWithAutoconnectModeResponse struct {
 	AttachToServersResponse *MulticastResponse
 }
 
-func (h *hubLoginer) LoginWithAutoconnectMode(username, password string) (*LoginWithAutoconnectModeResponse, error) {
-	hubSessionKey, err := h.LoginWithAuthRelayMode(username, password)
+func (h *hubLoginer) LoginWithAutoconnectMode(ctx context.Context, username, password string) (*LoginWithAutoconnectModeResponse, error) {
+	hubSessionKey, err := h.loginToHub(ctx, username, password, autoconnectLoginMode)
 	if err != nil {
 		return nil, err
 	}
-	userServerIDs, err := h.uyuniServerTopologyInfoRetriever.RetrieveUserServerIDs(h.hubAPIEndpoint, hubSessionKey, username)
+	userServerIDs, err := h.uyuniServerTopologyInfoRetriever.RetrieveUserServerIDs(ctx, h.hubAPIEndpoint, hubSessionKey, username)
 	if err != nil {
 		return nil, err
 	}
-	attachToServersResponse, err := h.serverAuthenticator.AttachToServers(hubSessionKey, userServerIDs, nil)
+	attachToServersResponse, err := h.serverAuthenticator.AttachToServers(ctx, hubSessionKey, userServerIDs, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &LoginWithAutoconnectModeResponse{hubSessionKey, attachToServersResponse}, nil
 }
 
-func (h *hubLoginer) loginToHub(username, password string, loginMode int) (string, error) {
-	hubToken, err := h.uyuniAuthenticator.Login(h.hubAPIEndpoint, username, password)
+func (h *hubLoginer) loginToHub(ctx context.Context, username, password string, loginMode int) (string, error) {
+	hubToken, err := h.uyuniAuthenticator.Login(ctx, h.hubAPIEndpoint, username, password)
 	if err != nil {
-		log.Printf("Error ocurred while trying to login into the Hub: %v", err)
+		logging.Error(ctx, "Error ocurred while trying to login into the Hub", "error", err)
 		return "", err
 	}
 	h.hubSessionRepository.SaveHubSession(NewHubSession(hubToken, username, password, loginMode))
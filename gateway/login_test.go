@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
@@ -46,7 +47,7 @@ func Test_Login(t *testing.T) {
 
 			hubLoginer := NewHubLoginer("hub_API_endpoint", mockUyuniAuthenticator, mockServerAuthenticator, mockUyuniTopologyInfoRetrtiever, mockHubSessionRepository)
 
-			hubSessionKey, err := hubLoginer.Login("username", "password")
+			hubSessionKey, err := hubLoginer.Login(context.Background(), "username", "password")
 
 			if err != nil && tc.expectedErr != err.Error() {
 				t.Fatalf("Error during executing request: %v", err)
@@ -71,11 +72,12 @@ func Test_LoginWithAutoconnectMode(t *testing.T) {
 		return nil, nil
 	}
 	attachToServersResponse := &MulticastResponse{
+		[]int64{1, 2},
 		map[int64]ServerSuccessfulResponse{
-			1: ServerSuccessfulResponse{1, "1-serverEndpoint", "success_call"},
+			1: ServerSuccessfulResponse{1, "1-serverEndpoint", "success_call", 1},
 		},
 		map[int64]ServerFailedResponse{
-			2: ServerFailedResponse{2, "2-serverEndpoint", "failed_call"},
+			2: ServerFailedResponse{2, "2-serverEndpoint", "failed_call", 1, errors.New("failed_call"), 0},
 		},
 	}
 
@@ -133,7 +135,11 @@ func Test_LoginWithAutoconnectMode(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			mockHubSessionRepository := new(mockHubSessionRepository)
 			savedOnSession := false
-			mockHubSessionRepository.mockSaveHubSession = func(hubSession *HubSession) { savedOnSession = true }
+			savedLoginMode := manualLoginMode
+			mockHubSessionRepository.mockSaveHubSession = func(hubSession *HubSession) {
+				savedOnSession = true
+				savedLoginMode = hubSession.loginMode
+			}
 
 			mockUyuniAuthenticator := new(mockUyuniAuthenticator)
 			mockUyuniAuthenticator.mockLogin = tc.mockLogin(tc.hubSessionKey)
@@ -144,7 +150,7 @@ func Test_LoginWithAutoconnectMode(t *testing.T) {
 
 			hubLoginer := NewHubLoginer("hub_API_endpoint", mockUyuniAuthenticator, mockServerAuthenticator, mockUyuniTopologyInfoRetrtiever, mockHubSessionRepository)
 
-			loginWithAutoconnectModeResponse, err := hubLoginer.LoginWithAutoconnectMode("username", "password")
+			loginWithAutoconnectModeResponse, err := hubLoginer.LoginWithAutoconnectMode(context.Background(), "username", "password")
 
 			if err != nil && tc.expectedErr != err.Error() {
 				t.Fatalf("Error during executing request: %v", err)
@@ -155,6 +161,9 @@ func Test_LoginWithAutoconnectMode(t *testing.T) {
 			if err == nil && !savedOnSession {
 				t.Fatalf("HubSession was not saved as expected")
 			}
+			if err == nil && savedLoginMode != autoconnectLoginMode {
+				t.Fatalf("expected and actual login modes don't match. Actual was:  %v. Expected was: %v", savedLoginMode, autoconnectLoginMode)
+			}
 		})
 	}
 }
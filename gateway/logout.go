@@ -1,53 +1,67 @@
 package gateway
 
 import (
-	"errors"
-	"log"
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
 //HubLogouter provides an interface for logout operations
 type HubLogouter interface {
-	Logout(hubSessionKey string) error
+	Logout(ctx context.Context, hubSessionKey string) error
 }
 
 type hubLogouter struct {
 	hubAPIEndpoint       string
 	uyuniAuthenticator   UyuniAuthenticator
 	hubSessionRepository HubSessionRepository
+	serverCallScheduler  ServerCallScheduler
 }
 
 //NewHubLogouter instantiates a HubLogouter
-func NewHubLogouter(hubAPIEndpoint string, uyuniAuthenticator UyuniAuthenticator, hubSessionRepository HubSessionRepository) *hubLogouter {
-	return &hubLogouter{hubAPIEndpoint, uyuniAuthenticator, hubSessionRepository}
+func NewHubLogouter(hubAPIEndpoint string, uyuniAuthenticator UyuniAuthenticator, hubSessionRepository HubSessionRepository,
+	serverCallScheduler ServerCallScheduler) *hubLogouter {
+	return &hubLogouter{hubAPIEndpoint, uyuniAuthenticator, hubSessionRepository, serverCallScheduler}
 }
 
-func (h *hubLogouter) Logout(hubSessionKey string) error {
+func (h *hubLogouter) Logout(ctx context.Context, hubSessionKey string) error {
 	hubSession := h.hubSessionRepository.RetrieveHubSession(hubSessionKey)
 	if hubSession == nil {
-		log.Printf("HubSession was not found. HubSessionKey: %v", hubSessionKey)
-		return errors.New("Authentication error: provided session key is invalid")
+		logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+		return ErrInvalidHubSessionKey
 	}
-	err := h.uyuniAuthenticator.Logout(h.hubAPIEndpoint, hubSessionKey)
+	err := h.uyuniAuthenticator.Logout(ctx, h.hubAPIEndpoint, hubSessionKey)
 	if err != nil {
 		return err
 	}
-	h.logoutFromServersInHubSession(hubSession.ServerSessions)
+	logoutFromServers(ctx, h.serverCallScheduler, h.uyuniAuthenticator, hubSession.CopyServerSessions())
 	h.hubSessionRepository.RemoveHubSession(hubSessionKey)
 	return nil
 }
 
-func (h *hubLogouter) logoutFromServersInHubSession(serverSessions map[int64]*ServerSession) *MulticastResponse {
-	multicastCallRequest := h.generateLogoutMuticastCallRequest(serverSessions)
-	return executeCallOnServers(multicastCallRequest)
+//logoutFromServers logs out from the given servers in parallel. The servers the hub session failed to attach to
+//have no session to log out from, so they are reported as successful without calling them.
+func logoutFromServers(ctx context.Context, serverCallScheduler ServerCallScheduler, uyuniAuthenticator UyuniAuthenticator, serverSessions map[int64]*ServerSession) *MulticastResponse {
+	multicastCallRequest := generateLogoutMuticastCallRequest(uyuniAuthenticator, serverSessions)
+	logoutResponse := executeCallOnServers(ctx, serverCallScheduler, multicastCallRequest)
+	for serverID, serverSession := range serverSessions {
+		if !serverSession.IsAttached() {
+			logoutResponse.SuccessfulResponses[serverID] = ServerSuccessfulResponse{serverID, serverSession.serverAPIEndpoint, nil, 0}
+		}
+	}
+	return logoutResponse
 }
 
-func (h *hubLogouter) generateLogoutMuticastCallRequest(serverSessions map[int64]*ServerSession) *multicastCallRequest {
-	call := func(endpoint string, args []interface{}) (interface{}, error) {
-		return nil, h.uyuniAuthenticator.Logout(endpoint, args[0].(string))
+func generateLogoutMuticastCallRequest(uyuniAuthenticator UyuniAuthenticator, serverSessions map[int64]*ServerSession) *multicastCallRequest {
+	call := func(ctx context.Context, serverID int64, endpoint string, args []interface{}) (interface{}, error) {
+		return nil, uyuniAuthenticator.Logout(ctx, endpoint, args[0].(string))
 	}
 	serverCallInfos := make([]serverCallInfo, 0, len(serverSessions))
 	for serverID, serverSession := range serverSessions {
+		if !serverSession.IsAttached() {
+			continue
+		}
 		serverCallInfos = append(serverCallInfos, serverCallInfo{serverID, serverSession.serverAPIEndpoint, []interface{}{serverSession.serverSessionKey}})
 	}
-	return &multicastCallRequest{call, serverCallInfos}
+	return &multicastCallRequest{call, serverCallInfos, 0}
 }
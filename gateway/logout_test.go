@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -8,7 +9,7 @@ import (
 
 func Test_Logout(t *testing.T) {
 	mockRetrieveHubSessionFound := func(hubSessionKey string) *HubSession {
-		return &HubSession{"hubSessionKey", "username", "password", 1, make(map[int64]*ServerSession)}
+		return NewHubSession("hubSessionKey", "username", "password", 1)
 	}
 	tt := []struct {
 		name                   string
@@ -53,9 +54,9 @@ func Test_Logout(t *testing.T) {
 			mockUyuniAuthenticator := new(mockUyuniAuthenticator)
 			mockUyuniAuthenticator.mockLogout = tc.mockUyuniServerLogout
 
-			hubLogouter := NewHubLogouter("hub_API_endpoint", mockUyuniAuthenticator, mockHubSessionRepository)
+			hubLogouter := NewHubLogouter("hub_API_endpoint", mockUyuniAuthenticator, mockHubSessionRepository, NewServerCallScheduler(0))
 
-			err := hubLogouter.Logout("hubSessionKey")
+			err := hubLogouter.Logout(context.Background(), "hubSessionKey")
 
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
@@ -1,9 +1,16 @@
 package gateway
 
+import "context"
+
 type mockHubSessionRepository struct {
 	mockSaveHubSession     func(hubSession *HubSession)
 	mockRetrieveHubSession func(hubSessionKey string) *HubSession
+	mockPeekHubSession     func(hubSessionKey string) *HubSession
 	mockRemoveHubSession   func(hubSessionKey string)
+
+	mockRemoveExpiredHubSessions func() []*HubSession
+	mockCountHubSessions         func() int
+	mockRetrieveHubSessions      func() []*HubSession
 }
 
 func (m *mockHubSessionRepository) SaveHubSession(hubSession *HubSession) {
@@ -12,14 +19,27 @@ func (m *mockHubSessionRepository) SaveHubSession(hubSession *HubSession) {
 func (m *mockHubSessionRepository) RetrieveHubSession(hubSessionKey string) *HubSession {
 	return m.mockRetrieveHubSession(hubSessionKey)
 }
+func (m *mockHubSessionRepository) PeekHubSession(hubSessionKey string) *HubSession {
+	return m.mockPeekHubSession(hubSessionKey)
+}
 func (m *mockHubSessionRepository) RemoveHubSession(hubSessionKey string) {
 	m.mockRemoveHubSession(hubSessionKey)
 }
+func (m *mockHubSessionRepository) RemoveExpiredHubSessions() []*HubSession {
+	return m.mockRemoveExpiredHubSessions()
+}
+func (m *mockHubSessionRepository) CountHubSessions() int {
+	return m.mockCountHubSessions()
+}
+func (m *mockHubSessionRepository) RetrieveHubSessions() []*HubSession {
+	return m.mockRetrieveHubSessions()
+}
 
 type mockServerSessionRepository struct {
 	mockSaveServerSessions              func(hubSessionKey string, serverSessions map[int64]*ServerSession)
 	mockRetrieveServerSessionByServerID func(hubSessionKey string, serverID int64) *ServerSession
 	mockRetrieveServerSessions          func(hubSessionKey string) map[int64]*ServerSession
+	mockRemoveServerSessions            func(hubSessionKey string, serverIDs []int64)
 }
 
 func (m *mockServerSessionRepository) SaveServerSessions(hubSessionKey string, serverSessions map[int64]*ServerSession) {
@@ -31,17 +51,20 @@ func (m *mockServerSessionRepository) RetrieveServerSessionByServerID(hubSession
 func (m *mockServerSessionRepository) RetrieveServerSessions(hubSessionKey string) map[int64]*ServerSession {
 	return m.mockRetrieveServerSessions(hubSessionKey)
 }
+func (m *mockServerSessionRepository) RemoveServerSessions(hubSessionKey string, serverIDs []int64) {
+	m.mockRemoveServerSessions(hubSessionKey, serverIDs)
+}
 
 type mockUyuniAuthenticator struct {
 	mockLogin  func(endpoint, username, password string) (string, error)
 	mockLogout func(endpoint, sessionKey string) error
 }
 
-func (m *mockUyuniAuthenticator) Login(endpoint, username, password string) (string, error) {
+func (m *mockUyuniAuthenticator) Login(ctx context.Context, endpoint, username, password string) (string, error) {
 	return m.mockLogin(endpoint, username, password)
 }
 
-func (m *mockUyuniAuthenticator) Logout(endpoint, sessionKey string) error {
+func (m *mockUyuniAuthenticator) Logout(ctx context.Context, endpoint, sessionKey string) error {
 	return m.mockLogout(endpoint, sessionKey)
 }
 
@@ -51,15 +74,15 @@ type mockUyuniTopologyInfoRetriever struct {
 	mockRetrieveServerAPIEndpoints func(endpoint, sessionKey string, serverIDs []int64) (*RetrieveServerAPIEndpointsResponse, error)
 }
 
-func (m *mockUyuniTopologyInfoRetriever) ListServerIDs(endpoint, sessionKey string) ([]int64, error) {
+func (m *mockUyuniTopologyInfoRetriever) ListServerIDs(ctx context.Context, endpoint, sessionKey string) ([]int64, error) {
 	return m.mockListServerIDs(endpoint, sessionKey)
 }
 
-func (m *mockUyuniTopologyInfoRetriever) RetrieveUserServerIDs(endpoint, sessionKey, username string) ([]int64, error) {
+func (m *mockUyuniTopologyInfoRetriever) RetrieveUserServerIDs(ctx context.Context, endpoint, sessionKey, username string) ([]int64, error) {
 	return m.mockRetrieveUserServerIDs(endpoint, sessionKey, username)
 }
 
-func (m *mockUyuniTopologyInfoRetriever) RetrieveServerAPIEndpoints(endpoint, sessionKey string, serverIDs []int64) (*RetrieveServerAPIEndpointsResponse, error) {
+func (m *mockUyuniTopologyInfoRetriever) RetrieveServerAPIEndpoints(ctx context.Context, endpoint, sessionKey string, serverIDs []int64) (*RetrieveServerAPIEndpointsResponse, error) {
 	return m.mockRetrieveServerAPIEndpoints(endpoint, sessionKey, serverIDs)
 }
 
@@ -67,14 +90,32 @@ type mockUyuniCallExecutor struct {
 	mockExecuteCall func(endpoint string, call string, args []interface{}) (response interface{}, err error)
 }
 
-func (m *mockUyuniCallExecutor) ExecuteCall(endpoint string, call string, args []interface{}) (interface{}, error) {
+func (m *mockUyuniCallExecutor) ExecuteCall(ctx context.Context, endpoint string, call string, args []interface{}) (interface{}, error) {
 	return m.mockExecuteCall(endpoint, call, args)
 }
 
 type mockServerAuthenticator struct {
-	mockAttachToServers func(hubSessionKey string, serverIDs []int64, credentialsByServer map[int64]*Credentials) (*MulticastResponse, error)
+	mockAttachToServers        func(hubSessionKey string, serverIDs []int64, credentialsByServer map[int64]*Credentials) (*MulticastResponse, error)
+	mockDetachFromServers      func(hubSessionKey string, serverIDs []int64) (*MulticastResponse, error)
+	mockRetryFailedAttachments func(hubSessionKey string) (*MulticastResponse, error)
 }
 
-func (m *mockServerAuthenticator) AttachToServers(hubSessionKey string, serverIDs []int64, credentialsByServer map[int64]*Credentials) (*MulticastResponse, error) {
+func (m *mockServerAuthenticator) AttachToServers(ctx context.Context, hubSessionKey string, serverIDs []int64, credentialsByServer map[int64]*Credentials) (*MulticastResponse, error) {
 	return m.mockAttachToServers(hubSessionKey, serverIDs, credentialsByServer)
 }
+
+func (m *mockServerAuthenticator) DetachFromServers(ctx context.Context, hubSessionKey string, serverIDs []int64) (*MulticastResponse, error) {
+	return m.mockDetachFromServers(hubSessionKey, serverIDs)
+}
+
+func (m *mockServerAuthenticator) RetryFailedAttachments(ctx context.Context, hubSessionKey string) (*MulticastResponse, error) {
+	return m.mockRetryFailedAttachments(hubSessionKey)
+}
+
+type mockUyuniCircuitBreakerStateRetriever struct {
+	mockRetrieveCircuitBreakerState func(endpoint string) *CircuitBreakerState
+}
+
+func (m *mockUyuniCircuitBreakerStateRetriever) RetrieveCircuitBreakerState(endpoint string) *CircuitBreakerState {
+	return m.mockRetrieveCircuitBreakerState(endpoint)
+}
@@ -1,67 +1,82 @@
 package gateway
 
 import (
+	"context"
 	"errors"
-	"log"
+	"strconv"
 	"sync"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+	"github.com/uyuni-project/hub-xmlrpc-api/metrics"
 )
 
 type Multicaster interface {
-	Multicast(hubSessionKey string, call string, serverIDs []int64, argsByServer map[int64][]interface{}) (*MulticastResponse, error)
+	Multicast(ctx context.Context, hubSessionKey string, call string, serverIDs []int64, argsByServer map[int64][]interface{}, maxConcurrency int) (*MulticastResponse, error)
 }
 
 type multicaster struct {
 	uyuniCallExecutor    UyuniCallExecutor
 	hubSessionRepository HubSessionRepository
+	serverSessionRenewer ServerSessionRenewer
+	serverCallScheduler  ServerCallScheduler
 }
 
-func NewMulticaster(uyuniCallExecutor UyuniCallExecutor, hubSessionRepository HubSessionRepository) *multicaster {
-	return &multicaster{uyuniCallExecutor, hubSessionRepository}
+func NewMulticaster(uyuniCallExecutor UyuniCallExecutor, hubSessionRepository HubSessionRepository, serverSessionRenewer ServerSessionRenewer,
+	serverCallScheduler ServerCallScheduler) *multicaster {
+	return &multicaster{uyuniCallExecutor, hubSessionRepository, serverSessionRenewer, serverCallScheduler}
 }
 
-func (m *multicaster) Multicast(hubSessionKey string, call string, serverIDs []int64, argsByServer map[int64][]interface{}) (*MulticastResponse, error) {
+//Multicast executes the call on the given servers, running at most maxConcurrency calls at the same time (0 means no limit)
+func (m *multicaster) Multicast(ctx context.Context, hubSessionKey string, call string, serverIDs []int64, argsByServer map[int64][]interface{}, maxConcurrency int) (*MulticastResponse, error) {
 	hubSession := m.hubSessionRepository.RetrieveHubSession(hubSessionKey)
 	if hubSession == nil {
-		log.Printf("HubSession was not found. HubSessionKey: %v", hubSessionKey)
-		return nil, errors.New("Authentication error: provided session key is invalid")
+		logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+		return nil, ErrInvalidHubSessionKey
 	}
-	multicastCallRequest, err := m.generateMulticastCallRequest(call, hubSession.ServerSessions, serverIDs, argsByServer)
+	multicastCallRequest, err := m.generateMulticastCallRequest(ctx, call, hubSession.CopyServerSessions(), serverIDs, argsByServer)
 	if err != nil {
 		return nil, err
 	}
-	return executeCallOnServers(multicastCallRequest), nil
+	multicastCallRequest.maxConcurrency = maxConcurrency
+	return executeCallOnServers(ctx, m.serverCallScheduler, multicastCallRequest), nil
 }
 
 type multicastCallRequest struct {
 	call            serverCall
 	serverCallInfos []serverCallInfo
+	maxConcurrency  int
 }
 type serverCallInfo struct {
 	serverID int64
 	endpoint string
 	args     []interface{}
 }
-type serverCall func(endpoint string, args []interface{}) (interface{}, error)
+type serverCall func(ctx context.Context, serverID int64, endpoint string, args []interface{}) (interface{}, error)
 
-func (m *multicaster) generateMulticastCallRequest(call string, serverSessions map[int64]*ServerSession, serverIDs []int64, argsByServer map[int64][]interface{}) (*multicastCallRequest, error) {
-	callFunc := func(endpoint string, args []interface{}) (interface{}, error) {
-		return m.uyuniCallExecutor.ExecuteCall(endpoint, call, args)
+func (m *multicaster) generateMulticastCallRequest(ctx context.Context, call string, serverSessions map[int64]*ServerSession, serverIDs []int64, argsByServer map[int64][]interface{}) (*multicastCallRequest, error) {
+	//the server sessions of the requested servers are kept apart, as they may be renewed while the calls are executed
+	serverSessionsByID := make(map[int64]*ServerSession, len(serverIDs))
+	callFunc := func(ctx context.Context, serverID int64, endpoint string, args []interface{}) (interface{}, error) {
+		return executeCallOnServerSession(ctx, m.uyuniCallExecutor, m.serverSessionRenewer, serverSessionsByID[serverID], call, args)
 	}
 
 	serverCallInfos := make([]serverCallInfo, 0, len(argsByServer))
 	for _, serverID := range serverIDs {
 		if serverSession, ok := serverSessions[serverID]; ok {
-			args := append([]interface{}{serverSession.serverSessionKey}, argsByServer[serverID]...)
-			serverCallInfos = append(serverCallInfos, serverCallInfo{serverID, serverSession.serverAPIEndpoint, args})
+			serverSessionsByID[serverID] = serverSession
+			serverCallInfos = append(serverCallInfos, serverCallInfo{serverID, serverSession.serverAPIEndpoint, argsByServer[serverID]})
 		} else {
-			log.Printf("ServerSession was not found. ServerID: %v", serverID)
-			return nil, errors.New("Authentication error: provided session key is invalid")
+			logging.Error(ctx, "ServerSession was not found", "server_id", serverID)
+			return nil, ErrInvalidHubSessionKey
 		}
 	}
-	return &multicastCallRequest{callFunc, serverCallInfos}, nil
+	return &multicastCallRequest{callFunc, serverCallInfos, 0}, nil
 }
 
+//MulticastResponse holds the responses of the servers, which were called in the order of ServerIDs
 type MulticastResponse struct {
+	ServerIDs           []int64
 	SuccessfulResponses map[int64]ServerSuccessfulResponse
 	FailedResponses     map[int64]ServerFailedResponse
 }
@@ -69,38 +84,93 @@ type ServerSuccessfulResponse struct {
 	ServerID int64
 	endpoint string
 	Response interface{}
+	Attempts int
 }
 type ServerFailedResponse struct {
 	ServerID     int64
 	endpoint     string
 	ErrorMessage string
+	Attempts     int
+	Err          error
+	Elapsed      time.Duration
+}
+
+//Endpoint returns the endpoint the failed call was sent to
+func (r ServerFailedResponse) Endpoint() string {
+	return r.endpoint
 }
 
-func executeCallOnServers(multicastCallRequest *multicastCallRequest) *MulticastResponse {
+func executeCallOnServers(ctx context.Context, serverCallScheduler ServerCallScheduler, multicastCallRequest *multicastCallRequest) *MulticastResponse {
 	var mutexForSuccesfulResponses = &sync.Mutex{}
 	var mutexForFailedResponses = &sync.Mutex{}
 
+	serverIDs := make([]int64, 0, len(multicastCallRequest.serverCallInfos))
 	successfulResponses := make(map[int64]ServerSuccessfulResponse)
 	failedResponses := make(map[int64]ServerFailedResponse)
 
+	progress := multicastProgressFromContext(ctx)
+
 	var wg sync.WaitGroup
 	wg.Add(len(multicastCallRequest.serverCallInfos))
 
+	tasks := make([]func(), 0, len(multicastCallRequest.serverCallInfos))
 	for _, serverCallInfo := range multicastCallRequest.serverCallInfos {
-		go func(call serverCall, endpoint string, args []interface{}, serverID int64) {
+		call, endpoint, args, serverID := multicastCallRequest.call, serverCallInfo.endpoint, serverCallInfo.args, serverCallInfo.serverID
+		serverIDs = append(serverIDs, serverID)
+		tasks = append(tasks, func() {
 			defer wg.Done()
-			response, err := call(endpoint, args)
+			//the request may have been cancelled while the call was waiting to be scheduled
+			var response interface{}
+			trace := &ServerCallTrace{ServerID: serverID}
+			var elapsed time.Duration
+			err := ctx.Err()
+			if err == nil {
+				start := time.Now()
+				response, err = call(WithServerCallTrace(ctx, trace), serverID, endpoint, args)
+				elapsed = time.Since(start)
+				metrics.ObserveServerCall(strconv.FormatInt(serverID, 10), err == nil, elapsed)
+				//executors which do not retry calls may not fill in the trace
+				if trace.Attempts == 0 && !errors.Is(err, ErrServerNotAttached) {
+					trace.Attempts = 1
+				}
+			}
 			if err != nil {
+				failedResponse := ServerFailedResponse{serverID, endpoint, err.Error(), trace.Attempts, err, elapsed}
 				mutexForFailedResponses.Lock()
-				failedResponses[serverID] = ServerFailedResponse{serverID, endpoint, err.Error()}
+				failedResponses[serverID] = failedResponse
 				mutexForFailedResponses.Unlock()
+				if progress != nil {
+					progress.recordFailedResponse(failedResponse)
+				}
 			} else {
+				successfulResponse := ServerSuccessfulResponse{serverID, endpoint, response, trace.Attempts}
 				mutexForSuccesfulResponses.Lock()
-				successfulResponses[serverID] = ServerSuccessfulResponse{serverID, endpoint, response}
+				successfulResponses[serverID] = successfulResponse
 				mutexForSuccesfulResponses.Unlock()
+				if progress != nil {
+					progress.recordSuccessfulResponse(successfulResponse)
+				}
 			}
-		}(multicastCallRequest.call, serverCallInfo.endpoint, serverCallInfo.args, serverCallInfo.serverID)
+		})
 	}
+	serverCallScheduler.Schedule(tasks, multicastCallRequest.maxConcurrency)
 	wg.Wait()
-	return &MulticastResponse{successfulResponses, failedResponses}
+	return &MulticastResponse{serverIDs, successfulResponses, failedResponses}
+}
+
+//multicastProgress is notified of every server response as soon as it is received
+type multicastProgress interface {
+	recordSuccessfulResponse(response ServerSuccessfulResponse)
+	recordFailedResponse(response ServerFailedResponse)
+}
+
+type multicastProgressKey struct{}
+
+func withMulticastProgress(ctx context.Context, progress multicastProgress) context.Context {
+	return context.WithValue(ctx, multicastProgressKey{}, progress)
+}
+
+func multicastProgressFromContext(ctx context.Context) multicastProgress {
+	progress, _ := ctx.Value(multicastProgressKey{}).(multicastProgress)
+	return progress
 }
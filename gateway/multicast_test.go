@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"strconv"
@@ -16,18 +17,20 @@ func Test_executeCallOnServers(t *testing.T) {
 		{
 			name: "executeCallOnServers all_calls_successful",
 			multicastCallRequest: &multicastCallRequest{
-				func(endpoint string, args []interface{}) (interface{}, error) {
+				func(ctx context.Context, serverID int64, endpoint string, args []interface{}) (interface{}, error) {
 					return "success_call", nil
 				},
 				[]serverCallInfo{
 					serverCallInfo{1, "1-serverEndpoint", []interface{}{"1-sessionKey", "arg1_Server1"}},
 					serverCallInfo{2, "2-serverEndpoint", []interface{}{"2-sessionKey", "arg1_Server2"}},
 				},
+				0,
 			},
 			expectedMulticastResponse: &MulticastResponse{
+				[]int64{1, 2},
 				map[int64]ServerSuccessfulResponse{
-					1: ServerSuccessfulResponse{1, "1-serverEndpoint", "success_call"},
-					2: ServerSuccessfulResponse{2, "2-serverEndpoint", "success_call"},
+					1: ServerSuccessfulResponse{1, "1-serverEndpoint", "success_call", 1},
+					2: ServerSuccessfulResponse{2, "2-serverEndpoint", "success_call", 1},
 				},
 				map[int64]ServerFailedResponse{},
 			},
@@ -35,7 +38,7 @@ func Test_executeCallOnServers(t *testing.T) {
 		{
 			name: "executeCallOnServers first_call_successful_and_the_other_calls_failed",
 			multicastCallRequest: &multicastCallRequest{
-				func(endpoint string, args []interface{}) (interface{}, error) {
+				func(ctx context.Context, serverID int64, endpoint string, args []interface{}) (interface{}, error) {
 					if endpoint == "1-serverEndpoint" {
 						return "success_call", nil
 					}
@@ -45,32 +48,62 @@ func Test_executeCallOnServers(t *testing.T) {
 					serverCallInfo{1, "1-serverEndpoint", []interface{}{"1-sessionKey", "arg1_Server1"}},
 					serverCallInfo{2, "2-serverEndpoint", []interface{}{"2-sessionKey", "arg1_Server2"}},
 				},
+				0,
 			},
 			expectedMulticastResponse: &MulticastResponse{
+				[]int64{1, 2},
 				map[int64]ServerSuccessfulResponse{
-					1: ServerSuccessfulResponse{1, "1-serverEndpoint", "success_call"},
+					1: ServerSuccessfulResponse{1, "1-serverEndpoint", "success_call", 1},
 				},
 				map[int64]ServerFailedResponse{
-					2: ServerFailedResponse{2, "2-serverEndpoint", "call_error"},
+					2: ServerFailedResponse{2, "2-serverEndpoint", "call_error", 1, errors.New("call_error"), 0},
 				},
 			},
 		},
 		{
 			name: "executeCallOnServers all_calls_failed",
 			multicastCallRequest: &multicastCallRequest{
-				func(endpoint string, args []interface{}) (interface{}, error) {
+				func(ctx context.Context, serverID int64, endpoint string, args []interface{}) (interface{}, error) {
 					return nil, errors.New("call_error")
 				},
 				[]serverCallInfo{
 					serverCallInfo{1, "1-serverEndpoint", []interface{}{"1-sessionKey", "arg1_Server1"}},
 					serverCallInfo{2, "2-serverEndpoint", []interface{}{"2-sessionKey", "arg1_Server2"}},
 				},
+				0,
 			},
 			expectedMulticastResponse: &MulticastResponse{
+				[]int64{1, 2},
 				map[int64]ServerSuccessfulResponse{},
 				map[int64]ServerFailedResponse{
-					1: ServerFailedResponse{1, "1-serverEndpoint", "call_error"},
-					2: ServerFailedResponse{2, "2-serverEndpoint", "call_error"},
+					1: ServerFailedResponse{1, "1-serverEndpoint", "call_error", 1, errors.New("call_error"), 0},
+					2: ServerFailedResponse{2, "2-serverEndpoint", "call_error", 1, errors.New("call_error"), 0},
+				},
+			},
+		},
+		{
+			name: "executeCallOnServers calls_retried_by_executor",
+			multicastCallRequest: &multicastCallRequest{
+				func(ctx context.Context, serverID int64, endpoint string, args []interface{}) (interface{}, error) {
+					ServerCallTraceFromContext(ctx).Attempts = 3
+					if endpoint == "2-serverEndpoint" {
+						return nil, errors.New("call_error")
+					}
+					return "success_call", nil
+				},
+				[]serverCallInfo{
+					serverCallInfo{1, "1-serverEndpoint", []interface{}{"1-sessionKey", "arg1_Server1"}},
+					serverCallInfo{2, "2-serverEndpoint", []interface{}{"2-sessionKey", "arg1_Server2"}},
+				},
+				0,
+			},
+			expectedMulticastResponse: &MulticastResponse{
+				[]int64{1, 2},
+				map[int64]ServerSuccessfulResponse{
+					1: ServerSuccessfulResponse{1, "1-serverEndpoint", "success_call", 3},
+				},
+				map[int64]ServerFailedResponse{
+					2: ServerFailedResponse{2, "2-serverEndpoint", "call_error", 3, errors.New("call_error"), 0},
 				},
 			},
 		},
@@ -78,7 +111,7 @@ func Test_executeCallOnServers(t *testing.T) {
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			multicastResponse := executeCallOnServers(tc.multicastCallRequest)
+			multicastResponse := withoutElapsedTimes(executeCallOnServers(context.Background(), NewServerCallScheduler(0), tc.multicastCallRequest))
 
 			if !reflect.DeepEqual(multicastResponse, tc.expectedMulticastResponse) {
 				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", multicastResponse, tc.expectedMulticastResponse)
@@ -94,15 +127,17 @@ func Test_Multicast(t *testing.T) {
 			for serverID := range argsByServer {
 				strServerID := strconv.FormatInt(serverID, 10)
 				serverSessions[serverID] =
-					&ServerSession{serverID, strServerID + "-serverEndpoint", strServerID + "-sessionKey", hubSessionKey}
+					NewServerSession(serverID, strServerID+"-serverEndpoint", strServerID+"-sessionKey", hubSessionKey)
 			}
-			return &HubSession{"hubSessionKey", "username", "password", 1, serverSessions}
+			hubSession := NewHubSession("hubSessionKey", "username", "password", 1)
+			hubSession.ServerSessions = serverSessions
+			return hubSession
 		}
 	}
 	mockRetrieveHubSessionFoundWithEmptyServerSessions :=
 		func(argsByServer map[int64][]interface{}) func(hubSessionKey string) *HubSession {
 			return func(hubSessionKey string) *HubSession {
-				return &HubSession{"hubSessionKey", "username", "password", 1, make(map[int64]*ServerSession)}
+				return NewHubSession("hubSessionKey", "username", "password", 1)
 			}
 		}
 
@@ -137,9 +172,10 @@ func Test_Multicast(t *testing.T) {
 			mockRetrieveHubSession: mockRetrieveHubSessionFound,
 			mockExecuteCall:        mockExecuteCallSuccessful,
 			expectedMulticastResponse: &MulticastResponse{
+				[]int64{1, 2},
 				map[int64]ServerSuccessfulResponse{
-					1: ServerSuccessfulResponse{1, "1-serverEndpoint", "success_call"},
-					2: ServerSuccessfulResponse{2, "2-serverEndpoint", "success_call"},
+					1: ServerSuccessfulResponse{1, "1-serverEndpoint", "success_call", 1},
+					2: ServerSuccessfulResponse{2, "2-serverEndpoint", "success_call", 1},
 				},
 				map[int64]ServerFailedResponse{},
 			},
@@ -154,10 +190,11 @@ func Test_Multicast(t *testing.T) {
 			mockRetrieveHubSession: mockRetrieveHubSessionFound,
 			mockExecuteCall:        mockExecuteCallError,
 			expectedMulticastResponse: &MulticastResponse{
+				[]int64{1, 2},
 				map[int64]ServerSuccessfulResponse{},
 				map[int64]ServerFailedResponse{
-					1: ServerFailedResponse{1, "1-serverEndpoint", "call_error"},
-					2: ServerFailedResponse{2, "2-serverEndpoint", "call_error"},
+					1: ServerFailedResponse{1, "1-serverEndpoint", "call_error", 1, errors.New("call_error"), 0},
+					2: ServerFailedResponse{2, "2-serverEndpoint", "call_error", 1, errors.New("call_error"), 0},
 				},
 			},
 		},
@@ -191,9 +228,10 @@ func Test_Multicast(t *testing.T) {
 			mockUyuniCallExecutor := new(mockUyuniCallExecutor)
 			mockUyuniCallExecutor.mockExecuteCall = tc.mockExecuteCall
 
-			multicaster := NewMulticaster(mockUyuniCallExecutor, mockSession)
+			multicaster := NewMulticaster(mockUyuniCallExecutor, mockSession, nil, NewServerCallScheduler(0))
 
-			multicastResponse, err := multicaster.Multicast("hubSessionKey", "call", tc.serverIDs, tc.argsByServer)
+			multicastResponse, err := multicaster.Multicast(context.Background(), "hubSessionKey", "call", tc.serverIDs, tc.argsByServer, 0)
+			multicastResponse = withoutElapsedTimes(multicastResponse)
 
 			if err != nil && tc.expectedErr != err.Error() {
 				t.Fatalf("Error during executing request: %v", err)
@@ -204,3 +242,15 @@ func Test_Multicast(t *testing.T) {
 		})
 	}
 }
+
+//withoutElapsedTimes clears the time spent on the failed calls, which changes on every run
+func withoutElapsedTimes(multicastResponse *MulticastResponse) *MulticastResponse {
+	if multicastResponse == nil {
+		return nil
+	}
+	for serverID, failedResponse := range multicastResponse.FailedResponses {
+		failedResponse.Elapsed = 0
+		multicastResponse.FailedResponses[serverID] = failedResponse
+	}
+	return multicastResponse
+}
@@ -0,0 +1,149 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+const (
+	HealthCheckOK     = "ok"
+	HealthCheckFailed = "failed"
+)
+
+//readinessProbeCall is a public method which does not require authentication and is cheap to serve
+const readinessProbeCall = "api.getVersion"
+
+//ReadinessChecker provides an interface for checking whether the service can serve requests
+type ReadinessChecker interface {
+	CheckReadiness(ctx context.Context, hubSessionKey string) (*ReadinessReport, error)
+}
+
+//ReadinessReport holds the result of every check. Ready is only true if all of them succeeded.
+//The reachability of the peripheral servers is informative and does not affect it.
+type ReadinessReport struct {
+	Ready   bool
+	Checks  []HealthCheck
+	Servers []ServerReachability
+}
+
+type HealthCheck struct {
+	Name         string
+	Status       string
+	ErrorMessage string
+}
+
+type ServerReachability struct {
+	ServerID     int64
+	Endpoint     string
+	Status       string
+	ErrorMessage string
+}
+
+type readinessChecker struct {
+	hubAPIEndpoint       string
+	probeCallExecutor    UyuniCallExecutor
+	hubSessionRepository HubSessionRepository
+	serverCallScheduler  ServerCallScheduler
+}
+
+//NewReadinessChecker instantiates a ReadinessChecker. Probes are executed with probeCallExecutor,
+//which must neither retry the calls nor record their results in the circuit breakers of the servers.
+func NewReadinessChecker(hubAPIEndpoint string, probeCallExecutor UyuniCallExecutor, hubSessionRepository HubSessionRepository,
+	serverCallScheduler ServerCallScheduler) *readinessChecker {
+	return &readinessChecker{hubAPIEndpoint, probeCallExecutor, hubSessionRepository, serverCallScheduler}
+}
+
+//CheckReadiness validates the configuration and checks that the Hub is reachable.
+//If hubSessionKey is set, it also checks the peripheral servers attached to that hub session.
+func (r *readinessChecker) CheckReadiness(ctx context.Context, hubSessionKey string) (*ReadinessReport, error) {
+	var hubSession *HubSession
+	if hubSessionKey != "" {
+		//probing the servers is not a use of the hub session, so its idle timer is left untouched
+		if hubSession = r.hubSessionRepository.PeekHubSession(hubSessionKey); hubSession == nil {
+			logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+			return nil, ErrInvalidHubSessionKey
+		}
+	}
+
+	checks := []HealthCheck{
+		newHealthCheck("config", validateHubAPIEndpoint(r.hubAPIEndpoint)),
+	}
+	_, err := r.probeCallExecutor.ExecuteCall(ctx, r.hubAPIEndpoint, readinessProbeCall, []interface{}{})
+	checks = append(checks, newHealthCheck("hub", err))
+
+	ready := true
+	for _, check := range checks {
+		if check.Status != HealthCheckOK {
+			logging.Warn(ctx, "Readiness check failed", "check", check.Name, "error", check.ErrorMessage)
+			ready = false
+		}
+	}
+	report := &ReadinessReport{Ready: ready, Checks: checks}
+	if hubSession != nil {
+		report.Servers = r.checkServers(ctx, hubSession)
+	}
+	return report, nil
+}
+
+//checkServers probes every distinct endpoint of the servers attached to the hub session once, skipping the failed attachments,
+//and reports the result for all the servers sharing it
+func (r *readinessChecker) checkServers(ctx context.Context, hubSession *HubSession) []ServerReachability {
+	serverIDsByEndpoint := make(map[string][]int64)
+	for serverID, serverSession := range hubSession.CopyServerSessions() {
+		//the endpoints of the servers the hub session failed to attach to may be unknown
+		if !serverSession.IsAttached() {
+			continue
+		}
+		serverIDsByEndpoint[serverSession.serverAPIEndpoint] = append(serverIDsByEndpoint[serverSession.serverAPIEndpoint], serverID)
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	servers := make([]ServerReachability, 0)
+	tasks := make([]func(), 0, len(serverIDsByEndpoint))
+	for endpoint, serverIDs := range serverIDsByEndpoint {
+		endpoint, serverIDs := endpoint, serverIDs
+		wg.Add(1)
+		tasks = append(tasks, func() {
+			defer wg.Done()
+			err := ctx.Err()
+			if err == nil {
+				_, err = r.probeCallExecutor.ExecuteCall(ctx, endpoint, readinessProbeCall, []interface{}{})
+			}
+			check := newHealthCheck("server", err)
+			mutex.Lock()
+			defer mutex.Unlock()
+			for _, serverID := range serverIDs {
+				servers = append(servers, ServerReachability{serverID, endpoint, check.Status, check.ErrorMessage})
+			}
+		})
+	}
+	r.serverCallScheduler.Schedule(tasks, 0)
+	wg.Wait()
+
+	sort.Slice(servers, func(i, j int) bool { return servers[i].ServerID < servers[j].ServerID })
+	return servers
+}
+
+func newHealthCheck(name string, err error) HealthCheck {
+	if err != nil {
+		return HealthCheck{name, HealthCheckFailed, err.Error()}
+	}
+	return HealthCheck{name, HealthCheckOK, ""}
+}
+
+func validateHubAPIEndpoint(hubAPIEndpoint string) error {
+	hubAPIURL, err := url.Parse(hubAPIEndpoint)
+	if err != nil {
+		return err
+	}
+	if (hubAPIURL.Scheme != "http" && hubAPIURL.Scheme != "https") || hubAPIURL.Host == "" {
+		return errors.New("invalid Hub API URL: " + hubAPIEndpoint)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func Test_CheckReadiness(t *testing.T) {
+	hubSession := NewHubSession("hubSessionKey", "username", "password", 1)
+	hubSession.ServerSessions[2] = NewServerSession(2, "2-serverEndpoint", "2-sessionKey", "hubSessionKey")
+	hubSession.ServerSessions[1] = NewServerSession(1, "1-serverEndpoint", "1-sessionKey", "hubSessionKey")
+	hubSession.ServerSessions[3] = NewServerSession(3, "1-serverEndpoint", "3-sessionKey", "hubSessionKey")
+	hubSession.ServerSessions[4] = NewFailedServerSession(4, "", "endpoint_error", "hubSessionKey")
+
+	tt := []struct {
+		name            string
+		hubAPIEndpoint  string
+		hubSessionKey   string
+		mockExecuteCall func(endpoint string, call string, args []interface{}) (interface{}, error)
+		expectedReport  *ReadinessReport
+		expectedCalls   int
+		expectedErr     error
+	}{
+		{
+			name:           "CheckReadiness ready",
+			hubAPIEndpoint: "http://hub/rpc/api",
+			mockExecuteCall: func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				return "25", nil
+			},
+			expectedReport: &ReadinessReport{
+				Ready:  true,
+				Checks: []HealthCheck{{"config", HealthCheckOK, ""}, {"hub", HealthCheckOK, ""}},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:           "CheckReadiness hub_unreachable",
+			hubAPIEndpoint: "http://hub/rpc/api",
+			mockExecuteCall: func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				return nil, errors.New("connection refused")
+			},
+			expectedReport: &ReadinessReport{
+				Ready:  false,
+				Checks: []HealthCheck{{"config", HealthCheckOK, ""}, {"hub", HealthCheckFailed, "connection refused"}},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:           "CheckReadiness invalid_config",
+			hubAPIEndpoint: "hub/rpc/api",
+			mockExecuteCall: func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				return "25", nil
+			},
+			expectedReport: &ReadinessReport{
+				Ready:  false,
+				Checks: []HealthCheck{{"config", HealthCheckFailed, "invalid Hub API URL: hub/rpc/api"}, {"hub", HealthCheckOK, ""}},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:           "CheckReadiness with_servers",
+			hubAPIEndpoint: "http://hub/rpc/api",
+			hubSessionKey:  "hubSessionKey",
+			mockExecuteCall: func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				if endpoint == "2-serverEndpoint" {
+					return nil, errors.New("connection refused")
+				}
+				return "25", nil
+			},
+			expectedReport: &ReadinessReport{
+				Ready:  true,
+				Checks: []HealthCheck{{"config", HealthCheckOK, ""}, {"hub", HealthCheckOK, ""}},
+				Servers: []ServerReachability{
+					{1, "1-serverEndpoint", HealthCheckOK, ""},
+					{2, "2-serverEndpoint", HealthCheckFailed, "connection refused"},
+					{3, "1-serverEndpoint", HealthCheckOK, ""},
+				},
+			},
+			expectedCalls: 3,
+		},
+		{
+			name:           "CheckReadiness invalid_hub_session_key",
+			hubAPIEndpoint: "http://hub/rpc/api",
+			hubSessionKey:  "otherHubSessionKey",
+			expectedErr:    ErrInvalidHubSessionKey,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mockHubSessionRepository := new(mockHubSessionRepository)
+			mockHubSessionRepository.mockPeekHubSession = func(hubSessionKey string) *HubSession {
+				if hubSessionKey == hubSession.HubSessionKey {
+					return hubSession
+				}
+				return nil
+			}
+			var calls int32
+			mockUyuniCallExecutor := new(mockUyuniCallExecutor)
+			mockUyuniCallExecutor.mockExecuteCall = func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				return tc.mockExecuteCall(endpoint, call, args)
+			}
+
+			readinessChecker := NewReadinessChecker(tc.hubAPIEndpoint, mockUyuniCallExecutor, mockHubSessionRepository, NewServerCallScheduler(0))
+			report, err := readinessChecker.CheckReadiness(context.Background(), tc.hubSessionKey)
+
+			if err != tc.expectedErr {
+				t.Fatalf("expected and actual errors don't match. Actual was:  %v. Expected was: %v", err, tc.expectedErr)
+			}
+			if !reflect.DeepEqual(report, tc.expectedReport) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", report, tc.expectedReport)
+			}
+			if int(calls) != tc.expectedCalls {
+				t.Fatalf("expected and actual number of calls don't match. Actual was:  %v. Expected was: %v", calls, tc.expectedCalls)
+			}
+		})
+	}
+}
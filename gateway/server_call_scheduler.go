@@ -0,0 +1,94 @@
+package gateway
+
+import "sync"
+
+//ServerCallScheduler limits the number of calls to peripheral servers executed at the same time,
+//across all the requests being served
+type ServerCallScheduler interface {
+	Schedule(tasks []func(), maxConcurrency int)
+}
+
+//serverCallScheduler runs server calls on a bounded number of workers. Pending calls are picked
+//in a round-robin fashion from each request, so a big multicast cannot starve the other requests.
+type serverCallScheduler struct {
+	mutex      sync.Mutex
+	maxWorkers int
+	running    int
+	queues     []*serverCallQueue
+	next       int
+}
+
+type serverCallQueue struct {
+	tasks          []func()
+	running        int
+	maxConcurrency int
+}
+
+//NewServerCallScheduler instantiates a ServerCallScheduler running at most maxWorkers calls at the same time.
+//A value of 0 means no limit.
+func NewServerCallScheduler(maxWorkers int) *serverCallScheduler {
+	return &serverCallScheduler{maxWorkers: maxWorkers}
+}
+
+//Schedule queues the tasks of a request, running at most maxConcurrency of them at the same time.
+//A maxConcurrency of 0 means the request is only limited by the available workers.
+func (s *serverCallScheduler) Schedule(tasks []func(), maxConcurrency int) {
+	if len(tasks) == 0 {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.queues = append(s.queues, &serverCallQueue{tasks: tasks, maxConcurrency: maxConcurrency})
+	s.dispatch()
+}
+
+//dispatch starts as many queued tasks as the limits allow. It must be called holding the mutex.
+func (s *serverCallScheduler) dispatch() {
+	for s.maxWorkers <= 0 || s.running < s.maxWorkers {
+		queue := s.nextRunnableQueue()
+		if queue == nil {
+			return
+		}
+		task := queue.tasks[0]
+		queue.tasks = queue.tasks[1:]
+		if len(queue.tasks) == 0 {
+			s.removeQueue(queue)
+		}
+		queue.running++
+		s.running++
+		go s.run(queue, task)
+	}
+}
+
+func (s *serverCallScheduler) nextRunnableQueue() *serverCallQueue {
+	for i := 0; i < len(s.queues); i++ {
+		index := (s.next + i) % len(s.queues)
+		queue := s.queues[index]
+		if queue.maxConcurrency <= 0 || queue.running < queue.maxConcurrency {
+			s.next = index + 1
+			return queue
+		}
+	}
+	return nil
+}
+
+func (s *serverCallScheduler) removeQueue(queue *serverCallQueue) {
+	for i, q := range s.queues {
+		if q == queue {
+			s.queues = append(s.queues[:i], s.queues[i+1:]...)
+			if s.next > i {
+				s.next--
+			}
+			return
+		}
+	}
+}
+
+func (s *serverCallScheduler) run(queue *serverCallQueue, task func()) {
+	task()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	queue.running--
+	s.running--
+	s.dispatch()
+}
@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_serverCallScheduler_limits(t *testing.T) {
+	tt := []struct {
+		name                  string
+		maxWorkers            int
+		maxConcurrency        int
+		tasks                 int
+		expectedMaxConcurrent int
+	}{
+		{name: "schedule limited_by_workers", maxWorkers: 2, maxConcurrency: 0, tasks: 10, expectedMaxConcurrent: 2},
+		{name: "schedule limited_by_request", maxWorkers: 5, maxConcurrency: 3, tasks: 10, expectedMaxConcurrent: 3},
+		{name: "schedule unlimited", maxWorkers: 0, maxConcurrency: 0, tasks: 10, expectedMaxConcurrent: 10},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			scheduler := NewServerCallScheduler(tc.maxWorkers)
+
+			var mutex sync.Mutex
+			var wg sync.WaitGroup
+			concurrent, maxConcurrent := 0, 0
+			tasks := make([]func(), 0, tc.tasks)
+			for i := 0; i < tc.tasks; i++ {
+				tasks = append(tasks, func() {
+					defer wg.Done()
+					mutex.Lock()
+					concurrent++
+					if concurrent > maxConcurrent {
+						maxConcurrent = concurrent
+					}
+					mutex.Unlock()
+					time.Sleep(20 * time.Millisecond)
+					mutex.Lock()
+					concurrent--
+					mutex.Unlock()
+				})
+			}
+			wg.Add(tc.tasks)
+			scheduler.Schedule(tasks, tc.maxConcurrency)
+			wg.Wait()
+
+			if maxConcurrent != tc.expectedMaxConcurrent {
+				t.Fatalf("Expected and actual values don't match. Actual was: %v. Expected was: %v", maxConcurrent, tc.expectedMaxConcurrent)
+			}
+		})
+	}
+}
+
+func Test_serverCallScheduler_fairness(t *testing.T) {
+	scheduler := NewServerCallScheduler(1)
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	executionOrder := make([]string, 0)
+	release := make(chan struct{})
+	newTask := func(name string) func() {
+		return func() {
+			defer wg.Done()
+			<-release
+			mutex.Lock()
+			executionOrder = append(executionOrder, name)
+			mutex.Unlock()
+		}
+	}
+
+	wg.Add(6)
+	scheduler.Schedule([]func(){newTask("big"), newTask("big"), newTask("big"), newTask("big")}, 0)
+	scheduler.Schedule([]func(){newTask("small"), newTask("small")}, 0)
+	close(release)
+	wg.Wait()
+
+	expectedOrder := []string{"big", "small", "big", "small", "big", "big"}
+	for i := range expectedOrder {
+		if executionOrder[i] != expectedOrder[i] {
+			t.Fatalf("Expected and actual values don't match. Actual was: %v. Expected was: %v", executionOrder, expectedOrder)
+		}
+	}
+}
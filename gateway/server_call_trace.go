@@ -0,0 +1,24 @@
+package gateway
+
+import "context"
+
+//ServerCallTrace collects details about how a call to a peripheral server was executed.
+//It is carried by the context passed to the UyuniCallExecutor: the gateway sets the ID of the called server,
+//and the UyuniCallExecutor fills in the rest.
+type ServerCallTrace struct {
+	ServerID int64
+	Attempts int
+}
+
+type serverCallTraceKey struct{}
+
+//WithServerCallTrace returns a copy of ctx carrying the given trace
+func WithServerCallTrace(ctx context.Context, trace *ServerCallTrace) context.Context {
+	return context.WithValue(ctx, serverCallTraceKey{}, trace)
+}
+
+//ServerCallTraceFromContext returns the trace carried by ctx, or nil if there is none
+func ServerCallTraceFromContext(ctx context.Context) *ServerCallTrace {
+	trace, _ := ctx.Value(serverCallTraceKey{}).(*ServerCallTrace)
+	return trace
+}
@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+//keepAliveCall is a cheap call which, unlike api.getVersion, needs a session key, so it refreshes the session on the server
+const keepAliveCall = "api.getApiNamespaces"
+
+//ServerSessionPinger keeps alive the server sessions of the active hub sessions by calling every server periodically
+type ServerSessionPinger interface {
+	PingServerSessions(ctx context.Context)
+	ListServerSessionStates(ctx context.Context, hubSessionKey string) ([]ServerSessionState, error)
+}
+
+//ServerSessionState tells whether the session of a server answered the last keep-alive call.
+//A server session is stale when that call failed, so the following calls to that server will likely fail too.
+type ServerSessionState struct {
+	ServerID           int64
+	Endpoint           string
+	LastSuccessfulPing time.Time
+	Stale              bool
+	LastPingError      string
+}
+
+type serverSessionPinger struct {
+	uyuniCallExecutor    UyuniCallExecutor
+	hubSessionRepository HubSessionRepository
+	serverSessionRenewer ServerSessionRenewer
+	serverCallScheduler  ServerCallScheduler
+
+	mutex      sync.Mutex
+	pingStates map[serverSessionRef]*serverPingState
+}
+
+type serverSessionRef struct {
+	hubSessionKey string
+	serverID      int64
+}
+
+type serverPingState struct {
+	lastSuccessfulPing time.Time
+	lastPingError      string
+}
+
+//NewServerSessionPinger instantiates a ServerSessionPinger. The sessions which expired anyway are renewed if possible
+func NewServerSessionPinger(uyuniCallExecutor UyuniCallExecutor, hubSessionRepository HubSessionRepository, serverSessionRenewer ServerSessionRenewer,
+	serverCallScheduler ServerCallScheduler) *serverSessionPinger {
+	return &serverSessionPinger{
+		uyuniCallExecutor:    uyuniCallExecutor,
+		hubSessionRepository: hubSessionRepository,
+		serverSessionRenewer: serverSessionRenewer,
+		serverCallScheduler:  serverCallScheduler,
+		pingStates:           make(map[serverSessionRef]*serverPingState),
+	}
+}
+
+//PingServerSessions executes the keep-alive call on every server attached to an active hub session, and records the result.
+//The results of the hub sessions which are no longer active are discarded.
+func (p *serverSessionPinger) PingServerSessions(ctx context.Context) {
+	activeServerSessions := make(map[serverSessionRef]bool)
+	for _, hubSession := range p.hubSessionRepository.RetrieveHubSessions() {
+		multicastResponse := p.pingHubSession(ctx, hubSession)
+		now := time.Now()
+
+		p.mutex.Lock()
+		for serverID := range multicastResponse.SuccessfulResponses {
+			ref := serverSessionRef{hubSession.HubSessionKey, serverID}
+			activeServerSessions[ref] = true
+			p.pingStates[ref] = &serverPingState{now, ""}
+		}
+		for serverID, response := range multicastResponse.FailedResponses {
+			ref := serverSessionRef{hubSession.HubSessionKey, serverID}
+			activeServerSessions[ref] = true
+			state, ok := p.pingStates[ref]
+			if !ok {
+				state = &serverPingState{}
+				p.pingStates[ref] = state
+			}
+			state.lastPingError = response.ErrorMessage
+			logging.Warn(ctx, "Server session keep-alive call failed", "hub_session_key", hubSession.HubSessionKey, "server_id", serverID, "error", response.ErrorMessage)
+		}
+		p.mutex.Unlock()
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for ref := range p.pingStates {
+		if !activeServerSessions[ref] {
+			delete(p.pingStates, ref)
+		}
+	}
+}
+
+func (p *serverSessionPinger) pingHubSession(ctx context.Context, hubSession *HubSession) *MulticastResponse {
+	//the server sessions are copied, as other requests may attach, renew or detach them while they are pinged
+	serverSessions := hubSession.CopyServerSessions()
+	serverSessionsByID := make(map[int64]*ServerSession, len(serverSessions))
+	serverCallInfos := make([]serverCallInfo, 0, len(serverSessions))
+	for serverID, serverSession := range serverSessions {
+		//there is no session to keep alive on the servers the hub session failed to attach to
+		if !serverSession.IsAttached() {
+			continue
+		}
+		serverSessionsByID[serverID] = serverSession
+		serverCallInfos = append(serverCallInfos, serverCallInfo{serverID, serverSession.serverAPIEndpoint, []interface{}{}})
+	}
+	callFunc := func(ctx context.Context, serverID int64, endpoint string, args []interface{}) (interface{}, error) {
+		return executeCallOnServerSession(ctx, p.uyuniCallExecutor, p.serverSessionRenewer, serverSessionsByID[serverID], keepAliveCall, args)
+	}
+	return executeCallOnServers(ctx, p.serverCallScheduler, &multicastCallRequest{callFunc, serverCallInfos, 0})
+}
+
+//ListServerSessionStates returns the keep-alive state of every server attached to the hub session, sorted by server ID
+func (p *serverSessionPinger) ListServerSessionStates(ctx context.Context, hubSessionKey string) ([]ServerSessionState, error) {
+	hubSession := p.hubSessionRepository.RetrieveHubSession(hubSessionKey)
+	if hubSession == nil {
+		logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+		return nil, ErrInvalidHubSessionKey
+	}
+	serverSessions := hubSession.CopyServerSessions()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	states := make([]ServerSessionState, 0, len(serverSessions))
+	for serverID, serverSession := range serverSessions {
+		state := ServerSessionState{ServerID: serverID, Endpoint: serverSession.serverAPIEndpoint}
+		if pingState, ok := p.pingStates[serverSessionRef{hubSessionKey, serverID}]; ok {
+			state.LastSuccessfulPing = pingState.lastSuccessfulPing
+			state.Stale = pingState.lastPingError != ""
+			state.LastPingError = pingState.lastPingError
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ServerID < states[j].ServerID })
+	return states, nil
+}
@@ -0,0 +1,112 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_PingServerSessions(t *testing.T) {
+	hubSession := NewHubSession("hubSessionKey", "username", "password", manualLoginMode)
+	hubSession.ServerSessions[1] = NewServerSession(1, "1-serverEndpoint", "1-sessionKey", "hubSessionKey")
+	hubSession.ServerSessions[2] = NewServerSession(2, "2-serverEndpoint", "2-sessionKey", "hubSessionKey")
+	hubSessions := []*HubSession{hubSession}
+
+	mockHubSessionRepository := new(mockHubSessionRepository)
+	mockHubSessionRepository.mockRetrieveHubSessions = func() []*HubSession {
+		return hubSessions
+	}
+	mockHubSessionRepository.mockRetrieveHubSession = func(hubSessionKey string) *HubSession {
+		if hubSessionKey == hubSession.HubSessionKey {
+			return hubSession
+		}
+		return nil
+	}
+	failingEndpoint := "2-serverEndpoint"
+	mockUyuniCallExecutor := new(mockUyuniCallExecutor)
+	mockUyuniCallExecutor.mockExecuteCall = func(endpoint string, call string, args []interface{}) (interface{}, error) {
+		if call != keepAliveCall || len(args) != 1 {
+			return nil, errors.New("unexpected call")
+		}
+		if endpoint == failingEndpoint {
+			return nil, errors.New("call_error")
+		}
+		return map[string]interface{}{}, nil
+	}
+
+	serverSessionPinger := NewServerSessionPinger(mockUyuniCallExecutor, mockHubSessionRepository, nil, NewServerCallScheduler(0))
+
+	states, _ := serverSessionPinger.ListServerSessionStates(context.Background(), "hubSessionKey")
+	if len(states) != 2 || states[0].Stale || !states[0].LastSuccessfulPing.IsZero() {
+		t.Fatalf("unexpected states before the first ping: %v", states)
+	}
+
+	before := time.Now()
+	serverSessionPinger.PingServerSessions(context.Background())
+	states, _ = serverSessionPinger.ListServerSessionStates(context.Background(), "hubSessionKey")
+	if states[0].ServerID != 1 || states[0].Stale || states[0].LastSuccessfulPing.Before(before) {
+		t.Fatalf("unexpected state of the alive server: %v", states[0])
+	}
+	if states[1].ServerID != 2 || !states[1].Stale || states[1].LastPingError != "call_error" || !states[1].LastSuccessfulPing.IsZero() {
+		t.Fatalf("unexpected state of the failing server: %v", states[1])
+	}
+
+	failingEndpoint = "1-serverEndpoint"
+	serverSessionPinger.PingServerSessions(context.Background())
+	states, _ = serverSessionPinger.ListServerSessionStates(context.Background(), "hubSessionKey")
+	if !states[0].Stale || states[0].LastSuccessfulPing.Before(before) {
+		t.Fatalf("the last successful ping of a failing server should be kept: %v", states[0])
+	}
+	if states[1].Stale || states[1].LastSuccessfulPing.Before(before) {
+		t.Fatalf("a server answering again should not be stale: %v", states[1])
+	}
+
+	hubSessions = nil
+	serverSessionPinger.PingServerSessions(context.Background())
+	if len(serverSessionPinger.pingStates) != 0 {
+		t.Fatalf("the states of inactive hub sessions should be discarded: %v", serverSessionPinger.pingStates)
+	}
+
+	if _, err := serverSessionPinger.ListServerSessionStates(context.Background(), "invalidHubSessionKey"); err != ErrInvalidHubSessionKey {
+		t.Fatalf("expected and actual errors don't match. Actual was:  %v. Expected was: %v", err, ErrInvalidHubSessionKey)
+	}
+}
+
+func Test_PingServerSessionsWhileAttaching(t *testing.T) {
+	hubSession := NewHubSession("hubSessionKey", "username", "password", manualLoginMode)
+	mockHubSessionRepository := new(mockHubSessionRepository)
+	mockHubSessionRepository.mockRetrieveHubSessions = func() []*HubSession {
+		return []*HubSession{hubSession}
+	}
+	mockHubSessionRepository.mockRetrieveHubSession = func(hubSessionKey string) *HubSession {
+		return hubSession
+	}
+	mockUyuniCallExecutor := new(mockUyuniCallExecutor)
+	mockUyuniCallExecutor.mockExecuteCall = func(endpoint string, call string, args []interface{}) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	}
+	serverSessionPinger := NewServerSessionPinger(mockUyuniCallExecutor, mockHubSessionRepository, nil, NewServerCallScheduler(0))
+
+	//the servers are attached, as the repositories of the session package do, while the sessions are pinged and listed
+	var wg sync.WaitGroup
+	for serverID := int64(1); serverID <= 10; serverID++ {
+		wg.Add(2)
+		go func(serverID int64) {
+			defer wg.Done()
+			hubSession.SaveServerSessions(map[int64]*ServerSession{serverID: NewServerSession(serverID, "serverEndpoint", "sessionKey", "hubSessionKey")})
+		}(serverID)
+		go func() {
+			defer wg.Done()
+			serverSessionPinger.PingServerSessions(context.Background())
+			serverSessionPinger.ListServerSessionStates(context.Background(), "hubSessionKey")
+		}()
+	}
+	wg.Wait()
+
+	states, _ := serverSessionPinger.ListServerSessionStates(context.Background(), "hubSessionKey")
+	if len(states) != 10 {
+		t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", len(states), 10)
+	}
+}
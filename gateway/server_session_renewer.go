@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+//invalidServerSessionFaultCode is the code of the fault returned by the peripheral servers
+//when they don't accept the session key of a call, usually because the session expired after some inactivity
+const invalidServerSessionFaultCode = 2950
+
+var errServerSessionNotRenewable = errors.New("server sessions can only be renewed for hub sessions in relay or autoconnect mode")
+
+//ServerSessionRenewer logs in again to a peripheral server whose session expired
+type ServerSessionRenewer interface {
+	RenewServerSession(ctx context.Context, hubSessionKey string, serverID int64) (*ServerSession, error)
+}
+
+type serverSessionRenewer struct {
+	uyuniAuthenticator      UyuniAuthenticator
+	hubSessionRepository    HubSessionRepository
+	serverSessionRepository ServerSessionRepository
+}
+
+func NewServerSessionRenewer(uyuniAuthenticator UyuniAuthenticator, hubSessionRepository HubSessionRepository, serverSessionRepository ServerSessionRepository) *serverSessionRenewer {
+	return &serverSessionRenewer{uyuniAuthenticator, hubSessionRepository, serverSessionRepository}
+}
+
+//RenewServerSession logs in to the server with the credentials kept by the hub session, and replaces the server session
+//with the new one. Hub sessions in manual mode don't keep the credentials of the servers, so their sessions can't be renewed.
+//The hub session is peeked, as renewals started by the pinger must not keep idle hub sessions alive.
+func (r *serverSessionRenewer) RenewServerSession(ctx context.Context, hubSessionKey string, serverID int64) (*ServerSession, error) {
+	hubSession := r.hubSessionRepository.PeekHubSession(hubSessionKey)
+	if hubSession == nil {
+		logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+		return nil, ErrInvalidHubSessionKey
+	}
+	if !hubSession.relaysCredentials() {
+		return nil, errServerSessionNotRenewable
+	}
+	serverSession := hubSession.ServerSession(serverID)
+	if serverSession == nil {
+		logging.Error(ctx, "ServerSession was not found", "hub_session_key", hubSessionKey, "server_id", serverID)
+		return nil, ErrInvalidHubSessionKey
+	}
+	//the servers the hub session failed to attach to are only attached again by hub.retryFailedAttachments
+	if !serverSession.IsAttached() {
+		return nil, serverSession.notAttachedError()
+	}
+	serverSessionKey, err := r.uyuniAuthenticator.Login(ctx, serverSession.serverAPIEndpoint, hubSession.username, hubSession.password)
+	if err != nil {
+		return nil, err
+	}
+	renewedServerSession := NewServerSession(serverID, serverSession.serverAPIEndpoint, serverSessionKey, hubSessionKey)
+	//the sessions of several servers of the same hub session may be renewed at the same time,
+	//while other requests use them: the repository saves them under the lock of the hub session
+	r.serverSessionRepository.SaveServerSessions(hubSessionKey, map[int64]*ServerSession{serverID: renewedServerSession})
+	return renewedServerSession, nil
+}
+
+func isInvalidServerSessionError(err error) bool {
+	var fault interface{ FaultCode() int }
+	return errors.As(err, &fault) && fault.FaultCode() == invalidServerSessionFaultCode
+}
+
+//executeCallOnServerSession executes the call passing the key of the server session before the rest of the arguments.
+//The call is not executed at all if the hub session failed to attach to the server.
+//If the server doesn't accept the key, the session is renewed and the call is retried once.
+func executeCallOnServerSession(ctx context.Context, uyuniCallExecutor UyuniCallExecutor, serverSessionRenewer ServerSessionRenewer, serverSession *ServerSession, call string, args []interface{}) (interface{}, error) {
+	if !serverSession.IsAttached() {
+		return nil, serverSession.notAttachedError()
+	}
+	response, err := uyuniCallExecutor.ExecuteCall(ctx, serverSession.serverAPIEndpoint, call, append([]interface{}{serverSession.serverSessionKey}, args...))
+	if err == nil || !isInvalidServerSessionError(err) {
+		return response, err
+	}
+	renewedServerSession, renewErr := serverSessionRenewer.RenewServerSession(ctx, serverSession.hubSessionKey, serverSession.serverID)
+	if renewErr != nil {
+		logging.Warn(ctx, "Server session could not be renewed", "server_id", serverSession.serverID, "error", renewErr)
+		return nil, err
+	}
+	logging.Info(ctx, "Server session renewed, retrying the call", "server_id", serverSession.serverID, "call", call)
+	trace := ServerCallTraceFromContext(ctx)
+	previousAttempts := 0
+	if trace != nil {
+		previousAttempts = trace.Attempts
+	}
+	response, err = uyuniCallExecutor.ExecuteCall(ctx, renewedServerSession.serverAPIEndpoint, call, append([]interface{}{renewedServerSession.serverSessionKey}, args...))
+	if trace != nil {
+		trace.Attempts += previousAttempts
+	}
+	return response, err
+}
@@ -0,0 +1,189 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type mockServerFault struct {
+	code int
+}
+
+func (m *mockServerFault) Error() string  { return "server_fault" }
+func (m *mockServerFault) FaultCode() int { return m.code }
+
+func Test_executeCallOnServerSession(t *testing.T) {
+	invalidSessionFault := &mockServerFault{invalidServerSessionFaultCode}
+
+	tt := []struct {
+		name                   string
+		loginMode              int
+		mockExecuteCall        func(endpoint string, call string, args []interface{}) (interface{}, error)
+		mockLogin              func(endpoint, username, password string) (string, error)
+		storedServerSession    *ServerSession
+		expectedResponse       interface{}
+		expectedErr            error
+		expectedServerSessions map[int64]*ServerSession
+	}{
+		{
+			name:      "executeCallOnServerSession expired_session_renewed",
+			loginMode: relayLoginMode,
+			mockExecuteCall: func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				if args[0] != "renewedSessionKey" {
+					return nil, invalidSessionFault
+				}
+				return "success_call", nil
+			},
+			mockLogin: func(endpoint, username, password string) (string, error) {
+				return "renewedSessionKey", nil
+			},
+			expectedResponse: "success_call",
+			expectedServerSessions: map[int64]*ServerSession{
+				1: NewServerSession(1, "1-serverEndpoint", "renewedSessionKey", "hubSessionKey"),
+			},
+		},
+		{
+			name:      "executeCallOnServerSession manual_login_mode",
+			loginMode: manualLoginMode,
+			mockExecuteCall: func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				return nil, invalidSessionFault
+			},
+			expectedErr: invalidSessionFault,
+		},
+		{
+			name:      "executeCallOnServerSession login_failed",
+			loginMode: relayLoginMode,
+			mockExecuteCall: func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				return nil, invalidSessionFault
+			},
+			mockLogin: func(endpoint, username, password string) (string, error) {
+				return "", errors.New("login_error")
+			},
+			expectedErr: invalidSessionFault,
+		},
+		{
+			name:      "executeCallOnServerSession other_fault_not_renewed",
+			loginMode: relayLoginMode,
+			mockExecuteCall: func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				return nil, &mockServerFault{-210}
+			},
+			expectedErr: &mockServerFault{-210},
+		},
+		{
+			name:      "executeCallOnServerSession failed_attachment_not_renewed",
+			loginMode: relayLoginMode,
+			mockExecuteCall: func(endpoint string, call string, args []interface{}) (interface{}, error) {
+				return nil, invalidSessionFault
+			},
+			storedServerSession: NewFailedServerSession(1, "", "endpoint_error", "hubSessionKey"),
+			expectedErr:         invalidSessionFault,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			serverSession := NewServerSession(1, "1-serverEndpoint", "1-sessionKey", "hubSessionKey")
+			hubSession := NewHubSession("hubSessionKey", "username", "password", tc.loginMode)
+			hubSession.ServerSessions[1] = serverSession
+			if tc.storedServerSession != nil {
+				hubSession.ServerSessions[1] = tc.storedServerSession
+			}
+			lastAccessedAt := hubSession.LastAccessedAt()
+
+			mockHubSessionRepository := new(mockHubSessionRepository)
+			mockHubSessionRepository.mockPeekHubSession = func(hubSessionKey string) *HubSession {
+				return hubSession
+			}
+			mockHubSessionRepository.mockRetrieveHubSession = func(hubSessionKey string) *HubSession {
+				t.Fatalf("the renewal must not refresh the idle timer of the hub session")
+				return nil
+			}
+			var savedServerSessions map[int64]*ServerSession
+			mockServerSessionRepository := new(mockServerSessionRepository)
+			mockServerSessionRepository.mockSaveServerSessions = func(hubSessionKey string, serverSessions map[int64]*ServerSession) {
+				savedServerSessions = serverSessions
+			}
+			mockUyuniAuthenticator := new(mockUyuniAuthenticator)
+			mockUyuniAuthenticator.mockLogin = tc.mockLogin
+			mockUyuniCallExecutor := new(mockUyuniCallExecutor)
+			mockUyuniCallExecutor.mockExecuteCall = tc.mockExecuteCall
+
+			serverSessionRenewer := NewServerSessionRenewer(mockUyuniAuthenticator, mockHubSessionRepository, mockServerSessionRepository)
+			response, err := executeCallOnServerSession(context.Background(), mockUyuniCallExecutor, serverSessionRenewer, serverSession, "call", []interface{}{"arg1"})
+
+			if !reflect.DeepEqual(err, tc.expectedErr) {
+				t.Fatalf("expected and actual errors don't match. Actual was:  %v. Expected was: %v", err, tc.expectedErr)
+			}
+			if !reflect.DeepEqual(response, tc.expectedResponse) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", response, tc.expectedResponse)
+			}
+			if !reflect.DeepEqual(savedServerSessions, tc.expectedServerSessions) {
+				t.Fatalf("expected and actual server sessions don't match. Actual was:  %v. Expected was: %v", savedServerSessions, tc.expectedServerSessions)
+			}
+			if !hubSession.LastAccessedAt().Equal(lastAccessedAt) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", hubSession.LastAccessedAt(), lastAccessedAt)
+			}
+		})
+	}
+}
+
+func Test_RenewServerSessionWhileMulticasting(t *testing.T) {
+	hubSession := NewHubSession("hubSessionKey", "username", "password", relayLoginMode)
+	serverIDs := make([]int64, 0)
+	for serverID := int64(1); serverID <= 5; serverID++ {
+		strServerID := strconv.FormatInt(serverID, 10)
+		hubSession.ServerSessions[serverID] = NewServerSession(serverID, strServerID+"-serverEndpoint", "expiredSessionKey", "hubSessionKey")
+		serverIDs = append(serverIDs, serverID)
+	}
+
+	//the repositories are backed by the hub session, as the ones of the session package
+	mockHubSessionRepository := new(mockHubSessionRepository)
+	mockHubSessionRepository.mockRetrieveHubSession = func(hubSessionKey string) *HubSession {
+		return hubSession
+	}
+	mockHubSessionRepository.mockPeekHubSession = func(hubSessionKey string) *HubSession {
+		return hubSession
+	}
+	mockServerSessionRepository := new(mockServerSessionRepository)
+	mockServerSessionRepository.mockSaveServerSessions = func(hubSessionKey string, serverSessions map[int64]*ServerSession) {
+		hubSession.SaveServerSessions(serverSessions)
+	}
+	mockUyuniAuthenticator := new(mockUyuniAuthenticator)
+	mockUyuniAuthenticator.mockLogin = func(endpoint, username, password string) (string, error) {
+		return "renewedSessionKey", nil
+	}
+	mockUyuniCallExecutor := new(mockUyuniCallExecutor)
+	mockUyuniCallExecutor.mockExecuteCall = func(endpoint string, call string, args []interface{}) (interface{}, error) {
+		if args[0] != "renewedSessionKey" {
+			return nil, &mockServerFault{invalidServerSessionFaultCode}
+		}
+		return "success_call", nil
+	}
+
+	serverSessionRenewer := NewServerSessionRenewer(mockUyuniAuthenticator, mockHubSessionRepository, mockServerSessionRepository)
+	multicaster := NewMulticaster(mockUyuniCallExecutor, mockHubSessionRepository, serverSessionRenewer, NewServerCallScheduler(0))
+
+	//every multicast renews some of the sessions while the others read them
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			multicastResponse, err := multicaster.Multicast(context.Background(), "hubSessionKey", "call", serverIDs, map[int64][]interface{}{}, 0)
+			if err != nil || len(multicastResponse.FailedResponses) > 0 {
+				t.Errorf("Unexpected failure during multicast: %v %v", err, multicastResponse)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, serverID := range serverIDs {
+		if serverSessionKey := hubSession.ServerSession(serverID).ServerSessionKey(); serverSessionKey != "renewedSessionKey" {
+			t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", serverSessionKey, "renewedSessionKey")
+		}
+	}
+}
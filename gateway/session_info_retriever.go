@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+//loginModeNames maps the login modes to the names reported to the users
+var loginModeNames = map[int]string{
+	manualLoginMode:      "manual",
+	relayLoginMode:       "relay",
+	autoconnectLoginMode: "autoconnect",
+}
+
+//SessionInfoRetriever provides an interface for describing a hub session, without exposing any of its secrets
+type SessionInfoRetriever interface {
+	GetSessionInfo(ctx context.Context, hubSessionKey string) (*SessionInfo, error)
+}
+
+type SessionInfo struct {
+	LoginMode      string
+	Username       string
+	CreatedAt      time.Time
+	LastAccessedAt time.Time
+	Servers        []ServerAttachmentInfo
+}
+
+//ServerAttachmentInfo tells whether the hub session is attached to a server, or why attaching to it failed
+type ServerAttachmentInfo struct {
+	ServerID int64
+	Endpoint string
+	State    string
+	Error    string
+}
+
+type sessionInfoRetriever struct {
+	hubSessionRepository HubSessionRepository
+}
+
+//NewSessionInfoRetriever instantiates a SessionInfoRetriever
+func NewSessionInfoRetriever(hubSessionRepository HubSessionRepository) *sessionInfoRetriever {
+	return &sessionInfoRetriever{hubSessionRepository}
+}
+
+//GetSessionInfo describes the hub session and every server it was attached to, sorted by server ID.
+//Describing the hub session does not count as a use of it, so its idle timer and last access time are left untouched.
+func (r *sessionInfoRetriever) GetSessionInfo(ctx context.Context, hubSessionKey string) (*SessionInfo, error) {
+	hubSession := r.hubSessionRepository.PeekHubSession(hubSessionKey)
+	if hubSession == nil {
+		logging.Error(ctx, "HubSession was not found", "hub_session_key", hubSessionKey)
+		return nil, ErrInvalidHubSessionKey
+	}
+	serverSessions := hubSession.CopyServerSessions()
+	servers := make([]ServerAttachmentInfo, 0, len(serverSessions))
+	for serverID, serverSession := range serverSessions {
+		servers = append(servers, ServerAttachmentInfo{serverID, serverSession.serverAPIEndpoint, serverSession.attachmentState, serverSession.attachmentError})
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].ServerID < servers[j].ServerID })
+	return &SessionInfo{loginModeNames[hubSession.loginMode], hubSession.username, hubSession.CreatedAt, hubSession.LastAccessedAt(), servers}, nil
+}
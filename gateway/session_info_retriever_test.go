@@ -0,0 +1,59 @@
+package gateway
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_GetSessionInfo(t *testing.T) {
+	hubSession := NewHubSession("hubSessionKey", "username", "password", autoconnectLoginMode)
+	lastAccessedAt := time.Now().Add(-time.Hour)
+	hubSession.lastAccessedAt = lastAccessedAt.UnixNano()
+	hubSession.ServerSessions[2] = NewFailedServerSession(2, "2-serverEndpoint", "login_error", "hubSessionKey")
+	hubSession.ServerSessions[1] = NewServerSession(1, "1-serverEndpoint", "1-sessionKey", "hubSessionKey")
+
+	tt := []struct {
+		name                string
+		hubSessionKey       string
+		expectedSessionInfo *SessionInfo
+		expectedErr         string
+	}{
+		{
+			name:          "GetSessionInfo attached_and_failed_servers",
+			hubSessionKey: "hubSessionKey",
+			expectedSessionInfo: &SessionInfo{"autoconnect", "username", hubSession.CreatedAt, time.Unix(0, lastAccessedAt.UnixNano()), []ServerAttachmentInfo{
+				{1, "1-serverEndpoint", ServerAttachmentStateAttached, ""},
+				{2, "2-serverEndpoint", ServerAttachmentStateFailed, "login_error"},
+			}},
+		},
+		{
+			name:          "GetSessionInfo invalid_hub_session_key",
+			hubSessionKey: "invalidHubSessionKey",
+			expectedErr:   "Authentication error: provided session key is invalid",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mockHubSessionRepository := new(mockHubSessionRepository)
+			mockHubSessionRepository.mockPeekHubSession = func(hubSessionKey string) *HubSession {
+				if hubSessionKey == hubSession.HubSessionKey {
+					return hubSession
+				}
+				return nil
+			}
+
+			sessionInfoRetriever := NewSessionInfoRetriever(mockHubSessionRepository)
+			sessionInfo, err := sessionInfoRetriever.GetSessionInfo(context.Background(), tc.hubSessionKey)
+
+			if err != nil && tc.expectedErr != err.Error() {
+				t.Fatalf("Error during executing request: %v", err)
+			}
+			if err == nil && !reflect.DeepEqual(sessionInfo, tc.expectedSessionInfo) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", sessionInfo, tc.expectedSessionInfo)
+			}
+		})
+	}
+}
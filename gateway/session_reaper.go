@@ -0,0 +1,54 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+//HubSessionReaper provides an interface for cleaning up hub sessions
+type HubSessionReaper interface {
+	ReapExpiredHubSessions()
+	LogoutAllHubSessions(ctx context.Context)
+}
+
+type hubSessionReaper struct {
+	hubAPIEndpoint       string
+	uyuniAuthenticator   UyuniAuthenticator
+	hubSessionRepository HubSessionRepository
+	serverCallScheduler  ServerCallScheduler
+}
+
+//NewHubSessionReaper instantiates a HubSessionReaper
+func NewHubSessionReaper(hubAPIEndpoint string, uyuniAuthenticator UyuniAuthenticator, hubSessionRepository HubSessionRepository,
+	serverCallScheduler ServerCallScheduler) *hubSessionReaper {
+	return &hubSessionReaper{hubAPIEndpoint, uyuniAuthenticator, hubSessionRepository, serverCallScheduler}
+}
+
+//ReapExpiredHubSessions removes the expired hub sessions from the repository,
+//logging out from the Hub and from every peripheral server attached to them
+func (r *hubSessionReaper) ReapExpiredHubSessions() {
+	for _, hubSession := range r.hubSessionRepository.RemoveExpiredHubSessions() {
+		r.logout(context.Background(), hubSession)
+	}
+}
+
+//LogoutAllHubSessions removes all the hub sessions from the repository, expired or not,
+//logging out from the Hub and from every peripheral server attached to them
+func (r *hubSessionReaper) LogoutAllHubSessions(ctx context.Context) {
+	for _, hubSession := range r.hubSessionRepository.RemoveExpiredHubSessions() {
+		r.logout(ctx, hubSession)
+	}
+	for _, hubSession := range r.hubSessionRepository.RetrieveHubSessions() {
+		r.hubSessionRepository.RemoveHubSession(hubSession.HubSessionKey)
+		r.logout(ctx, hubSession)
+	}
+}
+
+func (r *hubSessionReaper) logout(ctx context.Context, hubSession *HubSession) {
+	err := r.uyuniAuthenticator.Logout(ctx, r.hubAPIEndpoint, hubSession.HubSessionKey)
+	if err != nil {
+		logging.Error(ctx, "Error ocurred while logging out from HubSession", "hub_session_key", hubSession.HubSessionKey, "error", err)
+	}
+	logoutFromServers(ctx, r.serverCallScheduler, r.uyuniAuthenticator, hubSession.CopyServerSessions())
+}
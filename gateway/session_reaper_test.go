@@ -0,0 +1,104 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func Test_ReapExpiredHubSessions(t *testing.T) {
+	tt := []struct {
+		name              string
+		mockHubLogout     func(endpoint, sessionKey string) error
+		expectedLoggedOut []string
+	}{
+		{
+			name:              "ReapExpiredHubSessions should_logout_from_hub_and_servers",
+			mockHubLogout:     func(endpoint, sessionKey string) error { return nil },
+			expectedLoggedOut: []string{"hub_API_endpoint", "1-serverEndpoint"},
+		},
+		{
+			name:              "ReapExpiredHubSessions hub_logout_error_should_still_logout_from_servers",
+			mockHubLogout:     func(endpoint, sessionKey string) error { return errors.New("logout_error") },
+			expectedLoggedOut: []string{"hub_API_endpoint", "1-serverEndpoint"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expiredHubSession := NewHubSession("hubSessionKey", "username", "password", 1)
+			expiredHubSession.ServerSessions[1] = NewServerSession(1, "1-serverEndpoint", "1-sessionKey", "hubSessionKey")
+
+			mockHubSessionRepository := new(mockHubSessionRepository)
+			mockHubSessionRepository.mockRemoveExpiredHubSessions = func() []*HubSession {
+				return []*HubSession{expiredHubSession}
+			}
+
+			var mutex sync.Mutex
+			loggedOut := make(map[string]bool)
+			mockUyuniAuthenticator := new(mockUyuniAuthenticator)
+			mockUyuniAuthenticator.mockLogout = func(endpoint, sessionKey string) error {
+				mutex.Lock()
+				loggedOut[endpoint] = true
+				mutex.Unlock()
+				if endpoint == "hub_API_endpoint" {
+					return tc.mockHubLogout(endpoint, sessionKey)
+				}
+				return nil
+			}
+
+			hubSessionReaper := NewHubSessionReaper("hub_API_endpoint", mockUyuniAuthenticator, mockHubSessionRepository, NewServerCallScheduler(0))
+
+			hubSessionReaper.ReapExpiredHubSessions()
+
+			for _, endpoint := range tc.expectedLoggedOut {
+				if !loggedOut[endpoint] {
+					t.Fatalf("Expected logout from %v was not executed", endpoint)
+				}
+			}
+		})
+	}
+}
+
+func Test_LogoutAllHubSessions(t *testing.T) {
+	expiredHubSession := NewHubSession("expiredHubSessionKey", "username", "password", 1)
+	expiredHubSession.ServerSessions[1] = NewServerSession(1, "1-serverEndpoint", "1-expiredSessionKey", "expiredHubSessionKey")
+	activeHubSession := NewHubSession("activeHubSessionKey", "username", "password", 1)
+	activeHubSession.ServerSessions[2] = NewServerSession(2, "2-serverEndpoint", "2-activeSessionKey", "activeHubSessionKey")
+
+	removedHubSessions := make(map[string]bool)
+	mockHubSessionRepository := new(mockHubSessionRepository)
+	mockHubSessionRepository.mockRemoveExpiredHubSessions = func() []*HubSession {
+		return []*HubSession{expiredHubSession}
+	}
+	mockHubSessionRepository.mockRetrieveHubSessions = func() []*HubSession {
+		return []*HubSession{activeHubSession}
+	}
+	mockHubSessionRepository.mockRemoveHubSession = func(hubSessionKey string) {
+		removedHubSessions[hubSessionKey] = true
+	}
+
+	var mutex sync.Mutex
+	loggedOut := make(map[string]bool)
+	mockUyuniAuthenticator := new(mockUyuniAuthenticator)
+	mockUyuniAuthenticator.mockLogout = func(endpoint, sessionKey string) error {
+		mutex.Lock()
+		loggedOut[sessionKey] = true
+		mutex.Unlock()
+		return nil
+	}
+
+	hubSessionReaper := NewHubSessionReaper("hub_API_endpoint", mockUyuniAuthenticator, mockHubSessionRepository, NewServerCallScheduler(0))
+
+	hubSessionReaper.LogoutAllHubSessions(context.Background())
+
+	for _, sessionKey := range []string{"expiredHubSessionKey", "1-expiredSessionKey", "activeHubSessionKey", "2-activeSessionKey"} {
+		if !loggedOut[sessionKey] {
+			t.Fatalf("Expected logout of %v was not executed", sessionKey)
+		}
+	}
+	if !removedHubSessions["activeHubSessionKey"] {
+		t.Fatalf("active HubSession was not removed as expected")
+	}
+}
@@ -1,32 +1,162 @@
 package gateway
 
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 type HubSession struct {
 	HubSessionKey, username, password string
 	loginMode                         int
-	ServerSessions                    map[int64]*ServerSession
+	//ServerSessions is shared by concurrent requests once the HubSession is saved in a repository,
+	//so from then on it must only be accessed through the methods of HubSession, which hold serverSessionsMutex
+	ServerSessions      map[int64]*ServerSession
+	serverSessionsMutex sync.RWMutex
+	CreatedAt           time.Time
+	lastAccessedAt      int64
 }
 
 func NewHubSession(hubSessionKey, username, password string, loginMode int) *HubSession {
-	return &HubSession{hubSessionKey, username, password, loginMode, make(map[int64]*ServerSession)}
+	now := time.Now()
+	return &HubSession{
+		HubSessionKey:  hubSessionKey,
+		username:       username,
+		password:       password,
+		loginMode:      loginMode,
+		ServerSessions: make(map[int64]*ServerSession),
+		CreatedAt:      now,
+		lastAccessedAt: now.UnixNano(),
+	}
+}
+
+//RestoreHubSession instantiates a HubSession previously persisted by a HubSessionRepository
+func RestoreHubSession(hubSessionKey, username, password string, loginMode int, createdAt time.Time) *HubSession {
+	return &HubSession{
+		HubSessionKey:  hubSessionKey,
+		username:       username,
+		password:       password,
+		loginMode:      loginMode,
+		ServerSessions: make(map[int64]*ServerSession),
+		CreatedAt:      createdAt,
+		lastAccessedAt: time.Now().UnixNano(),
+	}
+}
+
+func (h *HubSession) Username() string { return h.username }
+func (h *HubSession) Password() string { return h.password }
+func (h *HubSession) LoginMode() int   { return h.loginMode }
+
+//relaysCredentials tells whether the credentials of the hub session are used to log in to the peripheral servers,
+//as it happens in relay and autoconnect modes
+func (h *HubSession) relaysCredentials() bool {
+	return h.loginMode == relayLoginMode || h.loginMode == autoconnectLoginMode
+}
+
+//ServerSession returns the session of the given server, or nil if the HubSession has none
+func (h *HubSession) ServerSession(serverID int64) *ServerSession {
+	h.serverSessionsMutex.RLock()
+	defer h.serverSessionsMutex.RUnlock()
+	return h.ServerSessions[serverID]
+}
+
+//CopyServerSessions returns a snapshot of the server sessions, which can be iterated while other requests modify them
+func (h *HubSession) CopyServerSessions() map[int64]*ServerSession {
+	h.serverSessionsMutex.RLock()
+	defer h.serverSessionsMutex.RUnlock()
+	serverSessions := make(map[int64]*ServerSession, len(h.ServerSessions))
+	for serverID, serverSession := range h.ServerSessions {
+		serverSessions[serverID] = serverSession
+	}
+	return serverSessions
+}
+
+//SaveServerSessions adds the given server sessions, replacing the ones of the same servers
+func (h *HubSession) SaveServerSessions(serverSessions map[int64]*ServerSession) {
+	h.serverSessionsMutex.Lock()
+	defer h.serverSessionsMutex.Unlock()
+	for serverID, serverSession := range serverSessions {
+		h.ServerSessions[serverID] = serverSession
+	}
+}
+
+//RemoveServerSessions removes the sessions of the given servers
+func (h *HubSession) RemoveServerSessions(serverIDs []int64) {
+	h.serverSessionsMutex.Lock()
+	defer h.serverSessionsMutex.Unlock()
+	for _, serverID := range serverIDs {
+		delete(h.ServerSessions, serverID)
+	}
+}
+
+//Touch refreshes the idle timer of the HubSession
+func (h *HubSession) Touch() {
+	atomic.StoreInt64(&h.lastAccessedAt, time.Now().UnixNano())
+}
+
+//LastAccessedAt returns the last time the HubSession was used
+func (h *HubSession) LastAccessedAt() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&h.lastAccessedAt))
+}
+
+//IsExpired checks if the HubSession outlived its ttl or has been idle for longer than idleTimeout.
+//A zero duration disables the corresponding check.
+func (h *HubSession) IsExpired(ttl, idleTimeout time.Duration) bool {
+	now := time.Now()
+	if ttl > 0 && now.Sub(h.CreatedAt) > ttl {
+		return true
+	}
+	return idleTimeout > 0 && now.Sub(h.LastAccessedAt()) > idleTimeout
 }
 
+//Attachment states of a ServerSession
+const (
+	ServerAttachmentStateAttached = "attached"
+	ServerAttachmentStateFailed   = "failed"
+)
+
 type ServerSession struct {
 	serverID                                           int64
 	serverAPIEndpoint, serverSessionKey, hubSessionKey string
+	attachmentState, attachmentError                   string
 }
 
 func NewServerSession(serverID int64, serverEndpoinit, serverSessionKey, hubSessionKey string) *ServerSession {
-	return &ServerSession{serverID, serverEndpoinit, serverSessionKey, hubSessionKey}
+	return &ServerSession{serverID, serverEndpoinit, serverSessionKey, hubSessionKey, ServerAttachmentStateAttached, ""}
+}
+
+//NewFailedServerSession instantiates the ServerSession of a server the hub session could not be attached to.
+//It has no server session key, and the calls to the server fail with ErrServerNotAttached until the attachment is retried.
+func NewFailedServerSession(serverID int64, serverEndpoint, attachmentError, hubSessionKey string) *ServerSession {
+	return &ServerSession{serverID, serverEndpoint, "", hubSessionKey, ServerAttachmentStateFailed, attachmentError}
+}
+
+func (s *ServerSession) ServerID() int64           { return s.serverID }
+func (s *ServerSession) ServerAPIEndpoint() string { return s.serverAPIEndpoint }
+func (s *ServerSession) ServerSessionKey() string  { return s.serverSessionKey }
+func (s *ServerSession) HubSessionKey() string     { return s.hubSessionKey }
+func (s *ServerSession) AttachmentState() string   { return s.attachmentState }
+func (s *ServerSession) AttachmentError() string   { return s.attachmentError }
+func (s *ServerSession) IsAttached() bool          { return s.attachmentState == ServerAttachmentStateAttached }
+
+func (s *ServerSession) notAttachedError() error {
+	return fmt.Errorf("%w: %v", ErrServerNotAttached, s.attachmentError)
 }
 
 type HubSessionRepository interface {
 	SaveHubSession(hubSession *HubSession)
 	RetrieveHubSession(hubSessionKey string) *HubSession
+	PeekHubSession(hubSessionKey string) *HubSession
 	RemoveHubSession(hubSessionKey string)
+	RemoveExpiredHubSessions() []*HubSession
+	CountHubSessions() int
+	RetrieveHubSessions() []*HubSession
 }
 
 type ServerSessionRepository interface {
 	SaveServerSessions(hubSessionKey string, serverSessions map[int64]*ServerSession)
 	RetrieveServerSessionByServerID(hubSessionKey string, serverID int64) *ServerSession
 	RetrieveServerSessions(hubSessionKey string) map[int64]*ServerSession
+	RemoveServerSessions(hubSessionKey string, serverIDs []int64)
 }
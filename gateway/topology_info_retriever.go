@@ -1,9 +1,13 @@
 package gateway
 
-import "log"
+import (
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
 
 type TopologyInfoRetriever interface {
-	ListServerIDs(hubSessionKey string) ([]int64, error)
+	ListServerIDs(ctx context.Context, hubSessionKey string) ([]int64, error)
 }
 
 type topologyInfoRetriever struct {
@@ -15,10 +19,10 @@ func NewTopologyInfoRetriever(hubAPIEndpoint string, uyuniTopologyInfoRetriever
 	return &topologyInfoRetriever{hubAPIEndpoint, uyuniTopologyInfoRetriever}
 }
 
-func (h *topologyInfoRetriever) ListServerIDs(hubSessionKey string) ([]int64, error) {
-	serverIDs, err := h.uyuniTopologyInfoRetriever.ListServerIDs(h.hubAPIEndpoint, hubSessionKey)
+func (h *topologyInfoRetriever) ListServerIDs(ctx context.Context, hubSessionKey string) ([]int64, error) {
+	serverIDs, err := h.uyuniTopologyInfoRetriever.ListServerIDs(ctx, h.hubAPIEndpoint, hubSessionKey)
 	if err != nil {
-		log.Printf("Error occured while retrieving the list of serverIDs: %v", err)
+		logging.Error(ctx, "Error occured while retrieving the list of serverIDs", "error", err)
 		return nil, err
 	}
 	return serverIDs, nil
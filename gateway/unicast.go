@@ -1,29 +1,30 @@
 package gateway
 
 import (
-	"errors"
-	"log"
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
 type Unicaster interface {
-	Unicast(hubSessionKey string, call string, serverID int64, args []interface{}) (interface{}, error)
+	Unicast(ctx context.Context, hubSessionKey string, call string, serverID int64, args []interface{}) (interface{}, error)
 }
 
 type unicaster struct {
 	uyuniCallExecutor       UyuniCallExecutor
 	serverSessionRepository ServerSessionRepository
+	serverSessionRenewer    ServerSessionRenewer
 }
 
-func NewUnicaster(uyuniCallExecutor UyuniCallExecutor, serverSessionRepository ServerSessionRepository) *unicaster {
-	return &unicaster{uyuniCallExecutor, serverSessionRepository}
+func NewUnicaster(uyuniCallExecutor UyuniCallExecutor, serverSessionRepository ServerSessionRepository, serverSessionRenewer ServerSessionRenewer) *unicaster {
+	return &unicaster{uyuniCallExecutor, serverSessionRepository, serverSessionRenewer}
 }
 
-func (u *unicaster) Unicast(hubSessionKey string, call string, serverID int64, args []interface{}) (interface{}, error) {
+func (u *unicaster) Unicast(ctx context.Context, hubSessionKey string, call string, serverID int64, args []interface{}) (interface{}, error) {
 	serverSession := u.serverSessionRepository.RetrieveServerSessionByServerID(hubSessionKey, serverID)
 	if serverSession == nil {
-		log.Printf("ServerSession was not found. HubSessionKey: %v, ServerID: %v", hubSessionKey, serverID)
-		return nil, errors.New("Authentication error: provided session key is invalid")
+		logging.Error(ctx, "ServerSession was not found", "hub_session_key", hubSessionKey, "server_id", serverID)
+		return nil, ErrInvalidHubSessionKey
 	}
-	callArguments := append([]interface{}{serverSession.serverSessionKey}, args...)
-	return u.uyuniCallExecutor.ExecuteCall(serverSession.serverAPIEndpoint, call, callArguments)
+	return executeCallOnServerSession(WithServerCallTrace(ctx, &ServerCallTrace{ServerID: serverID}), u.uyuniCallExecutor, u.serverSessionRenewer, serverSession, call, args)
 }
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"strconv"
@@ -10,13 +11,17 @@ import (
 func Test_Unicast(t *testing.T) {
 	mockRetrieveServerSessionByServerIDFound := func(hubSessionKey string, serverID int64) *ServerSession {
 		strServerID := strconv.FormatInt(serverID, 10)
-		return &ServerSession{serverID, strServerID + "serverAPIEndpoint", strServerID + "serverSessionkey", hubSessionKey}
+		return NewServerSession(serverID, strServerID+"serverAPIEndpoint", strServerID+"serverSessionkey", hubSessionKey)
 	}
 
 	mockRetrieveServerSessionByServerIDNotFound := func(hubSessionKey string, serverID int64) *ServerSession {
 		return nil
 	}
 
+	mockRetrieveServerSessionByServerIDFailed := func(hubSessionKey string, serverID int64) *ServerSession {
+		return NewFailedServerSession(serverID, "serverAPIEndpoint", "login_error", hubSessionKey)
+	}
+
 	tt := []struct {
 		name                                string
 		serverID                            int64
@@ -53,6 +58,16 @@ func Test_Unicast(t *testing.T) {
 			mockRetrieveServerSessionByServerID: mockRetrieveServerSessionByServerIDNotFound,
 			expectedErr:                         "Authentication error: provided session key is invalid",
 		},
+		{
+			name:                                "Unicast server_not_attached",
+			serverID:                            1,
+			serverArgs:                          []interface{}{"arg1", "arg2"},
+			mockRetrieveServerSessionByServerID: mockRetrieveServerSessionByServerIDFailed,
+			mockExecuteCall: func(serverEndpoint string, call string, args []interface{}) (response interface{}, err error) {
+				return nil, errors.New("unexpected_call")
+			},
+			expectedErr: "Server not attached: login_error",
+		},
 	}
 
 	for _, tc := range tt {
@@ -63,9 +78,9 @@ func Test_Unicast(t *testing.T) {
 			mockUyuniCallExecutor := new(mockUyuniCallExecutor)
 			mockUyuniCallExecutor.mockExecuteCall = tc.mockExecuteCall
 
-			unicaster := NewUnicaster(mockUyuniCallExecutor, mockServerSessionRepository)
+			unicaster := NewUnicaster(mockUyuniCallExecutor, mockServerSessionRepository, nil)
 
-			response, err := unicaster.Unicast("hubSessionKey", "call", tc.serverID, tc.serverArgs)
+			response, err := unicaster.Unicast(context.Background(), "hubSessionKey", "call", tc.serverID, tc.serverArgs)
 
 			if err != nil && tc.expectedErr != err.Error() {
 				t.Fatalf("Error during executing request: %v", err)
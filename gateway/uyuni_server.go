@@ -1,8 +1,10 @@
 package gateway
 
+import "context"
+
 type UyuniAuthenticator interface {
-	Login(endpoint, username, password string) (string, error)
-	Logout(endpoint, sessionKey string) error
+	Login(ctx context.Context, endpoint, username, password string) (string, error)
+	Logout(ctx context.Context, endpoint, sessionKey string) error
 }
 
 type RetrieveServerAPIEndpointsResponse struct {
@@ -11,11 +13,26 @@ type RetrieveServerAPIEndpointsResponse struct {
 }
 
 type UyuniTopologyInfoRetriever interface {
-	ListServerIDs(endpoint, sessionKey string) ([]int64, error)
-	RetrieveUserServerIDs(endpoint, sessionKey, username string) ([]int64, error)
-	RetrieveServerAPIEndpoints(endpoint, sessionKey string, serverIDs []int64) (*RetrieveServerAPIEndpointsResponse, error)
+	ListServerIDs(ctx context.Context, endpoint, sessionKey string) ([]int64, error)
+	RetrieveUserServerIDs(ctx context.Context, endpoint, sessionKey, username string) ([]int64, error)
+	RetrieveServerAPIEndpoints(ctx context.Context, endpoint, sessionKey string, serverIDs []int64) (*RetrieveServerAPIEndpointsResponse, error)
 }
 
 type UyuniCallExecutor interface {
-	ExecuteCall(endpoint, call string, args []interface{}) (interface{}, error)
+	ExecuteCall(ctx context.Context, endpoint, call string, args []interface{}) (interface{}, error)
+}
+
+const (
+	CircuitBreakerClosed   = "closed"
+	CircuitBreakerOpen     = "open"
+	CircuitBreakerHalfOpen = "half-open"
+)
+
+type CircuitBreakerState struct {
+	State               string
+	ConsecutiveFailures int
+}
+
+type UyuniCircuitBreakerStateRetriever interface {
+	RetrieveCircuitBreakerState(endpoint string) *CircuitBreakerState
 }
@@ -1,17 +1,22 @@
 package initialization
 
 import (
-	"log"
+	"context"
 	"net/http"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/gorilla/rpc"
 	"github.com/uyuni-project/hub-xmlrpc-api/config"
 	"github.com/uyuni-project/hub-xmlrpc-api/controller"
+	"github.com/uyuni-project/hub-xmlrpc-api/controller/jsonrpc"
 	"github.com/uyuni-project/hub-xmlrpc-api/controller/parser"
 	"github.com/uyuni-project/hub-xmlrpc-api/controller/transformer"
 	"github.com/uyuni-project/hub-xmlrpc-api/controller/xmlrpc"
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+	"github.com/uyuni-project/hub-xmlrpc-api/metrics"
 	"github.com/uyuni-project/hub-xmlrpc-api/session"
 	"github.com/uyuni-project/hub-xmlrpc-api/uyuni"
 	"github.com/uyuni-project/hub-xmlrpc-api/uyuni/client"
@@ -23,58 +28,160 @@ func InitServer() {
 	//init config
 	conf := config.NewConfig()
 
+	//init logger
+	logger, err := logging.NewLogger(os.Stderr, conf.LogFormat)
+	if err != nil {
+		exitWithError("Error ocurred while initializing the logger", "error", err)
+	}
+	logging.SetLogger(logger)
+
 	//init xmlrpc client implementation
-	client := client.NewClient(conf.ConnectTimeout, conf.RequestTimeout)
+	client := client.NewClientWithConnectionPool(conf.ConnectTimeout, conf.RequestTimeout, conf.MaxIdleConns, conf.MaxIdleConnsPerServer, conf.IdleConnTimeout)
 
 	//init uyuni adapters
-	uyuniCallExecutor := uyuni.NewUyuniCallExecutor(client)
+	retryPolicy := uyuni.NewRetryPolicy(conf.ServerCallMaxRetries, time.Duration(conf.ServerCallRetryBackoff)*time.Millisecond,
+		time.Duration(conf.ServerCallRetryMaxBackoff)*time.Millisecond, conf.ServerCallRetryDenylist)
+	circuitBreakers := uyuni.NewCircuitBreakers(conf.CircuitBreakerFailureThreshold, time.Duration(conf.CircuitBreakerOpenTimeout)*time.Second)
+	timeoutPolicy := uyuni.NewTimeoutPolicy(conf.ServerConnectTimeouts, conf.ServerRequestTimeouts, conf.MethodRequestTimeouts)
+	uyuniCallExecutor := uyuni.NewUyuniCallExecutor(client, retryPolicy, circuitBreakers, timeoutPolicy)
 	uyuniAuthenticator := uyuni.NewUyuniAuthenticator(uyuniCallExecutor)
 	uyuniTopologyInfoRetriever := uyuni.NewUyuniTopologyInfoRetriever(uyuniCallExecutor, conf.UseSSL)
 
 	//init session storage
-	var syncMap sync.Map
-	hubSessionRepository := session.NewInMemoryHubSessionRepository(&syncMap)
-	serverSessionRepository := session.NewInMemoryServerSessionRepository(&syncMap)
+	hubSessionRepository, serverSessionRepository := initSessionRepositories(conf)
+	metrics.NewGaugeFunc("hub_xmlrpc_api_active_hub_sessions", "Number of hub sessions which are not expired.", func() float64 {
+		return float64(hubSessionRepository.CountHubSessions())
+	})
 
 	//init gateway
-	serverAuthenticator := gateway.NewServerAuthenticator(conf.HubAPIURL, uyuniAuthenticator, uyuniTopologyInfoRetriever, hubSessionRepository, serverSessionRepository)
+	serverCallScheduler := gateway.NewServerCallScheduler(conf.MaxConcurrentServerCalls)
+	serverAuthenticator := gateway.NewServerAuthenticator(conf.HubAPIURL, uyuniAuthenticator, uyuniTopologyInfoRetriever, hubSessionRepository, serverSessionRepository, serverCallScheduler)
 	hubLoginer := gateway.NewHubLoginer(conf.HubAPIURL, uyuniAuthenticator, serverAuthenticator, uyuniTopologyInfoRetriever, hubSessionRepository)
-	hubLogouter := gateway.NewHubLogouter(conf.HubAPIURL, uyuniAuthenticator, hubSessionRepository)
+	hubLogouter := gateway.NewHubLogouter(conf.HubAPIURL, uyuniAuthenticator, hubSessionRepository, serverCallScheduler)
 
 	hubProxy := gateway.NewHubProxy(conf.HubAPIURL, uyuniCallExecutor)
 	hubTopologyInfoRetriever := gateway.NewTopologyInfoRetriever(conf.HubAPIURL, uyuniTopologyInfoRetriever)
 
-	multicaster := gateway.NewMulticaster(uyuniCallExecutor, hubSessionRepository)
-	unicaster := gateway.NewUnicaster(uyuniCallExecutor, serverSessionRepository)
+	serverSessionRenewer := gateway.NewServerSessionRenewer(uyuniAuthenticator, hubSessionRepository, serverSessionRepository)
+	multicaster := gateway.NewMulticaster(uyuniCallExecutor, hubSessionRepository, serverSessionRenewer, serverCallScheduler)
+	asyncMulticaster := gateway.NewAsyncMulticaster(multicaster, hubSessionRepository, time.Duration(conf.JobRetention)*time.Second)
+	unicaster := gateway.NewUnicaster(uyuniCallExecutor, serverSessionRepository, serverSessionRenewer)
+
+	circuitBreakerInfoRetriever := gateway.NewCircuitBreakerInfoRetriever(circuitBreakers, hubSessionRepository)
+	sessionInfoRetriever := gateway.NewSessionInfoRetriever(hubSessionRepository)
+	//readiness probes are neither retried nor recorded by the circuit breakers, so that probing can't open them
+	probeCallExecutor := uyuni.NewUyuniCallExecutor(client, nil, nil, timeoutPolicy)
+	readinessChecker := gateway.NewReadinessChecker(conf.HubAPIURL, probeCallExecutor, hubSessionRepository, serverCallScheduler)
+
+	hubSessionReaper := gateway.NewHubSessionReaper(conf.HubAPIURL, uyuniAuthenticator, hubSessionRepository, serverCallScheduler)
+	go reapExpiredHubSessions(hubSessionReaper, time.Duration(conf.SessionReaperInterval)*time.Second)
+	serverSessionPinger := gateway.NewServerSessionPinger(uyuniCallExecutor, hubSessionRepository, serverSessionRenewer, serverCallScheduler)
+	go keepServerSessionsAlive(serverSessionPinger, time.Duration(conf.SessionKeepAliveInterval)*time.Second)
 
 	//init controllers
 	xmlrpcCodec := initCodec()
 	rpcServer.RegisterCodec(xmlrpcCodec, "text/xml")
+	rpcServer.RegisterCodec(jsonrpc.NewCodec(xmlrpcCodec), "application/json")
 
 	rpcServer.RegisterService(controller.NewServerAuthenticationController(serverAuthenticator, transformer.MulticastResponseTransformer), "")
 	rpcServer.RegisterService(controller.NewHubLoginController(hubLoginer, transformer.MulticastResponseTransformer), "")
 	rpcServer.RegisterService(controller.NewHubLogoutController(hubLogouter), "")
 	rpcServer.RegisterService(controller.NewHubProxyController(hubProxy), "")
 	rpcServer.RegisterService(controller.NewHubTopologyController(hubTopologyInfoRetriever), "")
+	rpcServer.RegisterService(controller.NewCircuitBreakerController(circuitBreakerInfoRetriever), "")
+	rpcServer.RegisterService(controller.NewServerSessionController(serverSessionPinger), "")
+	rpcServer.RegisterService(controller.NewSessionInfoController(sessionInfoRetriever), "")
 	rpcServer.RegisterService(controller.NewMulticastController(multicaster, transformer.MulticastResponseTransformer), "")
+	rpcServer.RegisterService(controller.NewMulticastJobController(asyncMulticaster, transformer.MulticastResponseTransformer), "")
 	rpcServer.RegisterService(controller.NewUnicastController(unicaster), "")
+	rpcServer.RegisterService(controller.NewIntrospectionController(xmlrpcCodec, hubProxy), "")
+	rpcServer.RegisterService(controller.NewMulticallController(xmlrpc.NewCallDispatcher(metrics.InstrumentHandler(rpcServer))), "")
 
 	//init server
-	http.Handle("/hub/rpc/api", rpcServer)
+	http.Handle(conf.APIPath, logging.RequestIDHandler(defaultToXMLContentType(jsonrpc.NewHandler(metrics.InstrumentHandler(rpcServer)))))
+	http.Handle("/metrics", metrics.Handler())
+	healthController := controller.NewHealthController(readinessChecker)
+	http.HandleFunc("/healthz", healthController.Healthz)
+	http.HandleFunc("/readyz", healthController.Readyz)
+
+	server, err := newServer(conf)
+	if err != nil {
+		exitWithError("Error ocurred while configuring the server", "error", err)
+	}
+	redirectServer := newRedirectServer(conf)
+	go listenAndServe(conf, server)
+	if redirectServer != nil {
+		go redirectToHTTPS(redirectServer)
+	}
+
+	waitForShutdownSignal()
+	shutdown(server, redirectServer, asyncMulticaster, hubSessionReaper, time.Duration(conf.ShutdownTimeout)*time.Second, conf.ShutdownLogout)
+}
+
+func initSessionRepositories(conf *config.Config) (gateway.HubSessionRepository, gateway.ServerSessionRepository) {
+	var syncMap sync.Map
+	sessionTTL := time.Duration(conf.SessionTTL) * time.Second
+	sessionIdleTimeout := time.Duration(conf.SessionIdleTimeout) * time.Second
+
+	switch conf.SessionStorage {
+	case "memory":
+		return session.NewInMemoryHubSessionRepository(&syncMap, sessionTTL, sessionIdleTimeout),
+			session.NewInMemoryServerSessionRepository(&syncMap, sessionTTL, sessionIdleTimeout)
+	case "file":
+		storage, err := session.NewFileSessionStorage(&syncMap, conf.SessionStoragePath, conf.SessionStorageKeyPath)
+		if err != nil {
+			exitWithError("Error ocurred while initializing the session storage", "error", err)
+		}
+		return session.NewFileHubSessionRepository(storage, sessionTTL, sessionIdleTimeout),
+			session.NewFileServerSessionRepository(storage, sessionTTL, sessionIdleTimeout)
+	}
+	exitWithError("Unknown session storage", "session_storage", conf.SessionStorage)
+	return nil, nil
+}
+
+func reapExpiredHubSessions(hubSessionReaper gateway.HubSessionReaper, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	for range time.Tick(interval) {
+		hubSessionReaper.ReapExpiredHubSessions()
+	}
+}
 
-	log.Println("Starting XML-RPC server on localhost:2830/hub/rpc/api")
-	log.Fatal(http.ListenAndServe(":2830", nil))
+func keepServerSessionsAlive(serverSessionPinger gateway.ServerSessionPinger, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	for range time.Tick(interval) {
+		serverSessionPinger.PingServerSessions(context.Background())
+	}
 }
 
 func initCodec() *xmlrpc.Codec {
 	var codec = xmlrpc.NewCodec()
 
 	codec.RegisterMapping("hub.login", "HubLoginController.Login", parser.LoginRequestParser)
-	codec.RegisterMapping("hub.loginWithAutoconnectMode", "HubLoginController.LoginWithAutoconnectMode", parser.LoginRequestParser)
+	codec.RegisterMapping("hub.loginWithAutoconnectMode", "HubLoginController.LoginWithAutoconnectMode", parser.LoginWithAutoconnectModeRequestParser)
 	codec.RegisterMapping("hub.loginWithAuthRelayMode", "HubLoginController.LoginWithAuthRelayMode", parser.LoginRequestParser)
 	codec.RegisterMapping("hub.logout", "HubLogoutController.Logout", parser.LoginRequestParser)
 	codec.RegisterMapping("hub.attachToServers", "ServerAuthenticationController.AttachToServers", parser.AttachToServersRequestParser)
+	codec.RegisterMapping("hub.detachFromServers", "ServerAuthenticationController.DetachFromServers", parser.DetachFromServersRequestParser)
+	codec.RegisterMapping("hub.retryFailedAttachments", "ServerAuthenticationController.RetryFailedAttachments", parser.RetryFailedAttachmentsRequestParser)
 	codec.RegisterMapping("hub.listServerIds", "HubTopologyController.ListServerIDs", parser.LoginRequestParser)
+	codec.RegisterMapping("hub.listServerCircuitBreakers", "CircuitBreakerController.ListServerCircuitBreakers", parser.LoginRequestParser)
+	codec.RegisterMapping("hub.listServerSessionStates", "ServerSessionController.ListServerSessionStates", parser.LoginRequestParser)
+	codec.RegisterMapping("hub.getSessionInfo", "SessionInfoController.GetSessionInfo", parser.LoginRequestParser)
+	codec.RegisterMapping("hub.submitMulticastJob", "MulticastJobController.SubmitMulticastJob", parser.SubmitMulticastJobRequestParser)
+	codec.RegisterMapping("hub.getJobStatus", "MulticastJobController.GetJobStatus", parser.LoginRequestParser)
+	codec.RegisterMapping("hub.getJobResult", "MulticastJobController.GetJobResult", parser.MulticastJobRequestParser)
+	codec.RegisterMapping("hub.cancelJob", "MulticastJobController.CancelJob", parser.LoginRequestParser)
+	codec.RegisterMapping("system.multicall", "MulticallController.Multicall", parser.MulticallRequestParser)
+	codec.RegisterMapping("system.listMethods", "IntrospectionController.ListMethods", parser.LoginRequestParser)
+	codec.RegisterMapping("system.methodSignature", "IntrospectionController.MethodSignature", parser.LoginRequestParser)
+	codec.RegisterMapping("system.methodHelp", "IntrospectionController.MethodHelp", parser.LoginRequestParser)
+	for mapping, description := range methodDescriptions {
+		codec.RegisterDescription(mapping, description)
+	}
 
 	codec.RegisterDefaultMethodForNamespace("multicast", "MulticastController.Multicast", parser.MulticastRequestParser)
 	codec.RegisterDefaultMethodForNamespace("unicast", "UnicastController.Unicast", parser.UnicastRequestParser)
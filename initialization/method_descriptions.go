@@ -0,0 +1,112 @@
+package initialization
+
+import "github.com/uyuni-project/hub-xmlrpc-api/controller"
+
+//methodDescriptions documents the methods served by the gateway itself for the introspection methods
+var methodDescriptions = map[string]controller.MethodDescription{
+	"hub.login": {
+		Signatures: [][]string{{"string", "string", "string"}},
+		Help: "Logs in to the Hub in manual authentication mode and returns the hubSessionKey.\n" +
+			"Parameters: string username, string password",
+	},
+	"hub.loginWithAuthRelayMode": {
+		Signatures: [][]string{{"string", "string", "string"}},
+		Help: "Logs in to the Hub in relay authentication mode, reusing the credentials for the peripheral servers, and returns the hubSessionKey.\n" +
+			"Parameters: string username, string password",
+	},
+	"hub.loginWithAutoconnectMode": {
+		Signatures: [][]string{{"struct", "string", "string"}, {"struct", "string", "string", "struct"}},
+		Help: "Logs in to the Hub and attaches to all the peripheral servers the user has access to, reusing the credentials. " +
+			"Returns the hubSessionKey and the result of attaching to every server.\n" +
+			"Parameters: string username, string password, optional struct options (legacyFailedResponses)",
+	},
+	"hub.logout": {
+		Signatures: [][]string{{"string", "string"}},
+		Help: "Logs out from the Hub and from all the attached peripheral servers.\n" +
+			"Parameters: string hubSessionKey",
+	},
+	"hub.attachToServers": {
+		Signatures: [][]string{{"struct", "string", "array"}, {"struct", "string", "array", "struct"},
+			{"struct", "string", "array", "array", "array"}, {"struct", "string", "array", "array", "array", "struct"}},
+		Help: "Attaches the hub session to the peripheral servers, logging in to each of them. " +
+			"Credentials are only passed in manual authentication mode.\n" +
+			"Parameters: string hubSessionKey, array serverIDs, array usernames (one per server), array passwords (one per server), " +
+			"optional struct options (legacyFailedResponses)",
+	},
+	"hub.detachFromServers": {
+		Signatures: [][]string{{"struct", "string", "array"}, {"struct", "string", "array", "struct"}},
+		Help: "Logs out from the peripheral servers and detaches them from the hub session, leaving the other servers attached. " +
+			"Returns the result of detaching from every server.\n" +
+			"Parameters: string hubSessionKey, array serverIDs, optional struct options (legacyFailedResponses)",
+	},
+	"hub.retryFailedAttachments": {
+		Signatures: [][]string{{"struct", "string"}, {"struct", "string", "struct"}},
+		Help: "Attaches the hub session again to the peripheral servers it failed to attach to, leaving the attached ones untouched. " +
+			"Only supported in relay and autoconnect modes. Returns the result of attaching to every server.\n" +
+			"Parameters: string hubSessionKey, optional struct options (legacyFailedResponses)",
+	},
+	"hub.listServerIds": {
+		Signatures: [][]string{{"array", "string"}},
+		Help: "Returns the IDs of the peripheral servers registered in the Hub.\n" +
+			"Parameters: string hubSessionKey",
+	},
+	"hub.listServerCircuitBreakers": {
+		Signatures: [][]string{{"array", "string"}},
+		Help: "Returns the circuit breaker state of every peripheral server attached to the hub session.\n" +
+			"Parameters: string hubSessionKey",
+	},
+	"hub.listServerSessionStates": {
+		Signatures: [][]string{{"array", "string"}},
+		Help: "Returns the keep-alive state of every peripheral server attached to the hub session: " +
+			"the time of its last successful keep-alive call, and whether the last one failed.\n" +
+			"Parameters: string hubSessionKey",
+	},
+	"hub.getSessionInfo": {
+		Signatures: [][]string{{"struct", "string"}},
+		Help: "Describes the hub session: its login mode, username, creation and last access time, " +
+			"and the attachment state of every peripheral server, with the reason why attaching to it failed, if any.\n" +
+			"Parameters: string hubSessionKey",
+	},
+	"hub.submitMulticastJob": {
+		Signatures: [][]string{{"string", "string", "string", "array"}},
+		Help: "Executes a call on multiple peripheral servers in the background and returns the jobID. " +
+			"Takes the same parameters as the multicast namespace after the method name.\n" +
+			"Parameters: string hubSessionKey, string method, array serverIDs, array arguments (one per server) for every parameter of the method, struct options (optional)",
+	},
+	"hub.getJobStatus": {
+		Signatures: [][]string{{"struct", "string", "string"}},
+		Help: "Returns the status and the progress of a multicast job.\n" +
+			"Parameters: string hubSessionKey, string jobID",
+	},
+	"hub.getJobResult": {
+		Signatures: [][]string{{"struct", "string", "string"}, {"struct", "string", "string", "struct"}},
+		Help: "Returns the responses received so far by a multicast job, in the same format as the multicast namespace.\n" +
+			"Parameters: string hubSessionKey, string jobID, struct options (optional)",
+	},
+	"hub.cancelJob": {
+		Signatures: [][]string{{"string", "string", "string"}},
+		Help: "Cancels the calls of a multicast job which are still pending.\n" +
+			"Parameters: string hubSessionKey, string jobID",
+	},
+	"system.multicall": {
+		Signatures: [][]string{{"array", "array"}},
+		Help: "Executes several calls in order. Returns, for every call, a one-element array holding its result or a struct holding its fault.\n" +
+			"Parameters: array calls, each one a struct with a string methodName and an array of params",
+	},
+	"system.listMethods": {
+		Signatures: [][]string{{"array"}, {"array", "string"}},
+		Help: "Returns the methods served by the Hub XMLRPC API itself. " +
+			"If a hubSessionKey is passed, it also returns the methods of the Hub API with the unicast and multicast namespaces.\n" +
+			"Parameters: string hubSessionKey (optional)",
+	},
+	"system.methodSignature": {
+		Signatures: [][]string{{"array", "string"}},
+		Help: "Returns the signatures of a method, each one listing the type of the returned value followed by the types of the parameters, or \"undef\" if they are not known.\n" +
+			"Parameters: string methodName",
+	},
+	"system.methodHelp": {
+		Signatures: [][]string{{"string", "string"}},
+		Help: "Returns the description of a method, or an empty string if it is not known.\n" +
+			"Parameters: string methodName",
+	},
+}
@@ -0,0 +1,100 @@
+package initialization
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/config"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+var errTLSCertificateIncomplete = errors.New("Both HUB_TLS_CERT_FILE and HUB_TLS_KEY_FILE must be set to enable TLS")
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+//newServer instantiates the server of the registered handlers on the configured address,
+//using TLS if a certificate is configured
+func newServer(conf *config.Config) (*http.Server, error) {
+	server := &http.Server{Addr: net.JoinHostPort(conf.ListenAddress, strconv.Itoa(conf.ListenPort))}
+	if conf.TLSCertFile == "" && conf.TLSKeyFile == "" {
+		return server, nil
+	}
+	if conf.TLSCertFile == "" || conf.TLSKeyFile == "" {
+		return nil, errTLSCertificateIncomplete
+	}
+	minVersion, ok := tlsVersions[conf.TLSMinVersion]
+	if !ok {
+		return nil, fmt.Errorf("unknown TLS version: %v", conf.TLSMinVersion)
+	}
+	server.TLSConfig = &tls.Config{MinVersion: minVersion}
+	return server, nil
+}
+
+//listenAndServe serves requests until the server is shut down
+func listenAndServe(conf *config.Config, server *http.Server) {
+	var err error
+	if server.TLSConfig == nil {
+		logging.Info(context.Background(), "Starting XML-RPC server", "url", "http://"+server.Addr+conf.APIPath)
+		err = server.ListenAndServe()
+	} else {
+		logging.Info(context.Background(), "Starting XML-RPC server", "url", "https://"+server.Addr+conf.APIPath)
+		err = server.ListenAndServeTLS(conf.TLSCertFile, conf.TLSKeyFile)
+	}
+	if err != http.ErrServerClosed {
+		exitWithError("Error ocurred while serving requests", "error", err)
+	}
+}
+
+//exitWithError logs a failure the server can't recover from and terminates the process
+func exitWithError(msg string, keyValues ...interface{}) {
+	logging.Error(context.Background(), msg, keyValues...)
+	os.Exit(1)
+}
+
+//defaultToXMLContentType keeps serving the XMLRPC clients which do not send a Content-Type header,
+//as the RPC server only defaults to a codec when a single one is registered
+func defaultToXMLContentType(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") == "" {
+			r.Header.Set("Content-Type", "text/xml")
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+//newRedirectServer instantiates the server redirecting the plain HTTP requests to the HTTPS server,
+//or returns nil if TLS or the redirect port are not configured
+func newRedirectServer(conf *config.Config) *http.Server {
+	if conf.TLSCertFile == "" || conf.TLSKeyFile == "" || conf.HTTPRedirectPort <= 0 {
+		return nil
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		target := "https://" + net.JoinHostPort(host, strconv.Itoa(conf.ListenPort)) + r.URL.RequestURI()
+		//308 makes the clients repeat the POST request with the same body, as required by XMLRPC calls
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+	return &http.Server{Addr: net.JoinHostPort(conf.ListenAddress, strconv.Itoa(conf.HTTPRedirectPort)), Handler: handler}
+}
+
+//redirectToHTTPS serves the redirects to the HTTPS server until the redirect server is shut down
+func redirectToHTTPS(redirectServer *http.Server) {
+	logging.Info(context.Background(), "Redirecting HTTP requests to HTTPS", "address", redirectServer.Addr)
+	if err := redirectServer.ListenAndServe(); err != http.ErrServerClosed {
+		exitWithError("Error ocurred while redirecting requests to HTTPS", "error", err)
+	}
+}
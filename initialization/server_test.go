@@ -0,0 +1,143 @@
+package initialization
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/config"
+)
+
+func Test_newServer(t *testing.T) {
+	tt := []struct {
+		name               string
+		certFile           string
+		keyFile            string
+		minVersion         string
+		expectedTLS        bool
+		expectedMinVersion uint16
+		expectedErr        string
+	}{
+		{name: "newServer without TLS", minVersion: "1.2"},
+		{name: "newServer TLS 1.0", certFile: "cert.pem", keyFile: "key.pem", minVersion: "1.0", expectedTLS: true, expectedMinVersion: tls.VersionTLS10},
+		{name: "newServer TLS 1.1", certFile: "cert.pem", keyFile: "key.pem", minVersion: "1.1", expectedTLS: true, expectedMinVersion: tls.VersionTLS11},
+		{name: "newServer TLS 1.2", certFile: "cert.pem", keyFile: "key.pem", minVersion: "1.2", expectedTLS: true, expectedMinVersion: tls.VersionTLS12},
+		{name: "newServer TLS 1.3", certFile: "cert.pem", keyFile: "key.pem", minVersion: "1.3", expectedTLS: true, expectedMinVersion: tls.VersionTLS13},
+		{name: "newServer unknown_TLS_version", certFile: "cert.pem", keyFile: "key.pem", minVersion: "1.4", expectedErr: "unknown TLS version: 1.4"},
+		{name: "newServer only_cert_file", certFile: "cert.pem", minVersion: "1.2", expectedErr: errTLSCertificateIncomplete.Error()},
+		{name: "newServer only_key_file", keyFile: "key.pem", minVersion: "1.2", expectedErr: errTLSCertificateIncomplete.Error()},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &config.Config{ListenAddress: "localhost", ListenPort: 2830, TLSCertFile: tc.certFile, TLSKeyFile: tc.keyFile, TLSMinVersion: tc.minVersion}
+
+			server, err := newServer(conf)
+
+			if tc.expectedErr != "" {
+				if err == nil || err.Error() != tc.expectedErr {
+					t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", err, tc.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if server.Addr != "localhost:2830" {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", server.Addr, "localhost:2830")
+			}
+			if (server.TLSConfig != nil) != tc.expectedTLS {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", server.TLSConfig != nil, tc.expectedTLS)
+			}
+			if tc.expectedTLS && server.TLSConfig.MinVersion != tc.expectedMinVersion {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", server.TLSConfig.MinVersion, tc.expectedMinVersion)
+			}
+		})
+	}
+}
+
+func Test_newRedirectServer_disabled(t *testing.T) {
+	tt := []struct {
+		name             string
+		certFile         string
+		keyFile          string
+		httpRedirectPort int
+	}{
+		{name: "newRedirectServer without TLS", httpRedirectPort: 80},
+		{name: "newRedirectServer only_cert_file", certFile: "cert.pem", httpRedirectPort: 80},
+		{name: "newRedirectServer without_redirect_port", certFile: "cert.pem", keyFile: "key.pem"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &config.Config{ListenPort: 2830, TLSCertFile: tc.certFile, TLSKeyFile: tc.keyFile, HTTPRedirectPort: tc.httpRedirectPort}
+
+			if redirectServer := newRedirectServer(conf); redirectServer != nil {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", redirectServer.Addr, nil)
+			}
+		})
+	}
+}
+
+func Test_newRedirectServer(t *testing.T) {
+	tt := []struct {
+		name             string
+		method           string
+		host             string
+		target           string
+		expectedLocation string
+	}{
+		{
+			name:             "newRedirectServer host_with_port",
+			method:           "POST",
+			host:             "hub.example.com:80",
+			target:           "/hub/rpc/api",
+			expectedLocation: "https://hub.example.com:2830/hub/rpc/api",
+		},
+		{
+			name:             "newRedirectServer host_without_port",
+			method:           "POST",
+			host:             "hub.example.com",
+			target:           "/hub/rpc/api",
+			expectedLocation: "https://hub.example.com:2830/hub/rpc/api",
+		},
+		{
+			name:             "newRedirectServer ipv6_host",
+			method:           "GET",
+			host:             "[::1]:80",
+			target:           "/hub/rpc/api",
+			expectedLocation: "https://[::1]:2830/hub/rpc/api",
+		},
+		{
+			name:             "newRedirectServer query_kept",
+			method:           "GET",
+			host:             "hub.example.com:80",
+			target:           "/metrics?name=hub%20calls",
+			expectedLocation: "https://hub.example.com:2830/metrics?name=hub%20calls",
+		},
+	}
+
+	conf := &config.Config{ListenAddress: "localhost", ListenPort: 2830, TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", HTTPRedirectPort: 80}
+	redirectServer := newRedirectServer(conf)
+	if redirectServer == nil || redirectServer.Addr != "localhost:80" {
+		t.Fatalf("unexpected redirect server: %v", redirectServer)
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(tc.method, tc.target, nil)
+			request.Host = tc.host
+			recorder := httptest.NewRecorder()
+
+			redirectServer.Handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != http.StatusPermanentRedirect {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", recorder.Code, http.StatusPermanentRedirect)
+			}
+			if location := recorder.Header().Get("Location"); location != tc.expectedLocation {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", location, tc.expectedLocation)
+			}
+		})
+	}
+}
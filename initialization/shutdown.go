@@ -0,0 +1,55 @@
+package initialization
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+//jobWaiter is implemented by the gateway components running operations which outlive the requests starting them
+type jobWaiter interface {
+	WaitForJobs(ctx context.Context) error
+}
+
+//waitForShutdownSignal blocks until SIGTERM or SIGINT is received
+func waitForShutdownSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	logging.Info(context.Background(), "Received signal, shutting down", "signal", (<-signals).String())
+	signal.Stop(signals)
+}
+
+//shutdown stops accepting requests and waits for the in-flight requests and jobs to finish, aborting them after timeout.
+//The HTTP to HTTPS redirect server is stopped as well, if it is running.
+//If logout is set, it then logs out from all the hub sessions and their peripheral server sessions.
+func shutdown(server, redirectServer *http.Server, jobWaiter jobWaiter, hubSessionReaper gateway.HubSessionReaper, timeout time.Duration, logout bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if redirectServer != nil {
+		if err := redirectServer.Shutdown(ctx); err != nil {
+			logging.Error(ctx, "Error ocurred while waiting for in-flight redirects", "error", err)
+			redirectServer.Close()
+		}
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		logging.Error(ctx, "Error ocurred while waiting for in-flight requests", "error", err)
+		server.Close()
+	}
+	if err := jobWaiter.WaitForJobs(ctx); err != nil {
+		logging.Error(ctx, "Error ocurred while waiting for running multicast jobs, they were cancelled", "error", err)
+	}
+
+	if logout {
+		logoutCtx, cancelLogout := context.WithTimeout(context.Background(), timeout)
+		defer cancelLogout()
+		hubSessionReaper.LogoutAllHubSessions(logoutCtx)
+	}
+	logging.Info(ctx, "Shutdown completed")
+}
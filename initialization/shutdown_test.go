@@ -0,0 +1,160 @@
+package initialization
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockJobWaiter struct {
+	mockWaitForJobs func(ctx context.Context) error
+}
+
+func (m *mockJobWaiter) WaitForJobs(ctx context.Context) error {
+	return m.mockWaitForJobs(ctx)
+}
+
+type mockHubSessionReaper struct {
+	mockReapExpiredHubSessions func()
+	mockLogoutAllHubSessions   func(ctx context.Context)
+}
+
+func (m *mockHubSessionReaper) ReapExpiredHubSessions() {
+	m.mockReapExpiredHubSessions()
+}
+
+func (m *mockHubSessionReaper) LogoutAllHubSessions(ctx context.Context) {
+	m.mockLogoutAllHubSessions(ctx)
+}
+
+//shutdownEvents records the steps of the shutdown in the order they happen
+type shutdownEvents struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (e *shutdownEvents) add(event string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *shutdownEvents) list() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return append([]string{}, e.events...)
+}
+
+//recordingListener records when the server shutting down closes it
+type recordingListener struct {
+	net.Listener
+	name   string
+	events *shutdownEvents
+}
+
+func (l *recordingListener) Close() error {
+	l.events.add(l.name)
+	return l.Listener.Close()
+}
+
+//startTestServer returns a server which is already serving requests, so that shutting it down closes its listener
+func startTestServer(t *testing.T, name string, events *shutdownEvents) *http.Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})}
+	go server.Serve(&recordingListener{listener, name, events})
+
+	response, err := http.Get("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response.Body.Close()
+	return server
+}
+
+func Test_shutdown(t *testing.T) {
+	tt := []struct {
+		name           string
+		redirectServer bool
+		logout         bool
+		jobsDuration   time.Duration
+		expectedEvents []string
+		expectedJobErr error
+	}{
+		{
+			name:           "shutdown with_redirect_server_and_logout",
+			redirectServer: true,
+			logout:         true,
+			expectedEvents: []string{"redirect_server", "server", "wait_for_jobs", "logout"},
+		},
+		{
+			name:           "shutdown without_logout",
+			redirectServer: true,
+			expectedEvents: []string{"redirect_server", "server", "wait_for_jobs"},
+		},
+		{
+			name:           "shutdown without_redirect_server",
+			logout:         true,
+			expectedEvents: []string{"server", "wait_for_jobs", "logout"},
+		},
+		{
+			name:           "shutdown jobs_finishing_before_timeout",
+			logout:         true,
+			jobsDuration:   50 * time.Millisecond,
+			expectedEvents: []string{"server", "wait_for_jobs", "logout"},
+		},
+		{
+			name:           "shutdown jobs_cancelled_after_timeout",
+			logout:         true,
+			jobsDuration:   time.Minute,
+			expectedEvents: []string{"server", "wait_for_jobs", "logout"},
+			expectedJobErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			events := new(shutdownEvents)
+			server := startTestServer(t, "server", events)
+			var redirectServer *http.Server
+			if tc.redirectServer {
+				redirectServer = startTestServer(t, "redirect_server", events)
+			}
+
+			var jobErr, logoutCtxErr error
+			jobWaiter := new(mockJobWaiter)
+			jobWaiter.mockWaitForJobs = func(ctx context.Context) error {
+				select {
+				case <-time.After(tc.jobsDuration):
+				case <-ctx.Done():
+					jobErr = ctx.Err()
+				}
+				events.add("wait_for_jobs")
+				return jobErr
+			}
+			hubSessionReaper := new(mockHubSessionReaper)
+			hubSessionReaper.mockLogoutAllHubSessions = func(ctx context.Context) {
+				logoutCtxErr = ctx.Err()
+				events.add("logout")
+			}
+
+			shutdown(server, redirectServer, jobWaiter, hubSessionReaper, 500*time.Millisecond, tc.logout)
+
+			if actualEvents := events.list(); !reflect.DeepEqual(actualEvents, tc.expectedEvents) {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", actualEvents, tc.expectedEvents)
+			}
+			if jobErr != tc.expectedJobErr {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", jobErr, tc.expectedJobErr)
+			}
+			if logoutCtxErr != nil {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", logoutCtxErr, nil)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package integration_tests
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -27,7 +28,7 @@ func Test_LoginWithManualMode(t *testing.T) {
 
 			//login
 			credentials := []interface{}{tc.username, tc.password}
-			loginResponse, err := client.ExecuteCall(gatewayServerURL, "hub.login", credentials)
+			loginResponse, err := client.ExecuteCall(context.Background(), gatewayServerURL, "hub.login", credentials)
 
 			// if it's no error then defer the call for closing body
 			if err != nil {
@@ -65,7 +66,7 @@ func Test_LoginWithAutoconnectMode(t *testing.T) {
 			client := client.NewClient(10, 10)
 			//login
 			credentials := []interface{}{tc.username, tc.password}
-			loginResponse, err := client.ExecuteCall(gatewayServerURL, "hub.loginWithAutoconnectMode", credentials)
+			loginResponse, err := client.ExecuteCall(context.Background(), gatewayServerURL, "hub.loginWithAutoconnectMode", credentials)
 			if err != nil {
 				if !strings.Contains(err.Error(), tc.expectedError) {
 					t.Fatalf("Unexpected Error message: `%v` doesn't contain `%v`", err, tc.expectedError)
@@ -102,7 +103,7 @@ func Test_LoginWithAuthRelayMode(t *testing.T) {
 
 			//login
 			credentials := []interface{}{tc.username, tc.password}
-			loginResponse, err := client.ExecuteCall(gatewayServerURL, "hub.loginWithAuthRelayMode", credentials)
+			loginResponse, err := client.ExecuteCall(context.Background(), gatewayServerURL, "hub.loginWithAuthRelayMode", credentials)
 
 			if err != nil {
 				if !strings.Contains(err.Error(), tc.expectedError) {
@@ -139,7 +140,7 @@ func Test_AttachToServers(t *testing.T) {
 
 			//login
 			credentials := []interface{}{tc.username, tc.password}
-			loginResponse, err := client.ExecuteCall(gatewayServerURL, "hub.loginWithAuthRelayMode", credentials)
+			loginResponse, err := client.ExecuteCall(context.Background(), gatewayServerURL, "hub.loginWithAuthRelayMode", credentials)
 
 			// if it's no error then defer the call for closing body
 			if err != nil {
@@ -157,7 +158,7 @@ func Test_AttachToServers(t *testing.T) {
 					loggedInServerIDs = append(loggedInServerIDs, serverID.id)
 				}
 				//Call attachToServers method
-				attachToServerResponse, err := client.ExecuteCall(gatewayServerURL, "hub.attachToServers", []interface{}{hubSessionKey, loggedInServerIDs})
+				attachToServerResponse, err := client.ExecuteCall(context.Background(), gatewayServerURL, "hub.attachToServers", []interface{}{hubSessionKey, loggedInServerIDs})
 				if err != nil {
 					t.Fatalf("Unexpected Error message: `%v`", err)
 					return
@@ -175,7 +176,7 @@ func validatePeripheralServersResponse(t *testing.T, authResponse interface{}) {
 	succeededServers := loginResponseMap["Successful"].(map[string]interface{})
 	failedServers := loginResponseMap["Failed"].(map[string]interface{})
 
-	if len(succeededServers) != len(peripheralServers) {
+	if len(succeededServers["ServerIds"].([]interface{})) != len(peripheralServers) {
 		t.Fatalf("Unexpected Result: Some servers failed unexpectedly")
 	}
 	if len(failedServers["ServerIds"].([]interface{})) != 0 {
@@ -1,6 +1,7 @@
 package integration_tests
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -32,13 +33,13 @@ func Test_ProxyCallToHub(t *testing.T) {
 			//setup env
 			client := client.NewClient(10, 10)
 			//login
-			loginResponse, err := client.ExecuteCall(gatewayServerURL, "hub.login", []interface{}{tc.loginCredentials.username, tc.loginCredentials.password})
+			loginResponse, err := client.ExecuteCall(context.Background(), gatewayServerURL, "hub.login", []interface{}{tc.loginCredentials.username, tc.loginCredentials.password})
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("Error occurred when executing login: %v", err)
 			}
 			hubSessionKey := loginResponse.(string)
 			//execute call
-			callResponse, err := client.ExecuteCall(gatewayServerURL, tc.call, []interface{}{hubSessionKey})
+			callResponse, err := client.ExecuteCall(context.Background(), gatewayServerURL, tc.call, []interface{}{hubSessionKey})
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("expected and actual errors don't match. Expected was:\n%v\nActual is:\n%v:", tc.expectedError, err.Error())
 			}
@@ -46,7 +47,7 @@ func Test_ProxyCallToHub(t *testing.T) {
 				t.Fatalf("Expected and actual responses don't match. Actual response is: %v", callResponse)
 			}
 			//logout
-			_, err = client.ExecuteCall(gatewayServerURL, "hub.logout", []interface{}{hubSessionKey})
+			_, err = client.ExecuteCall(context.Background(), gatewayServerURL, "hub.logout", []interface{}{hubSessionKey})
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("Error occurred when executing logout: %v", err)
 			}
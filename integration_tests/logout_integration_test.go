@@ -1,6 +1,7 @@
 package integration_tests
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -35,13 +36,13 @@ func Test_Logout(t *testing.T) {
 			//setup env
 			client := client.NewClient(10, 10)
 			//login
-			loginResponse, err := client.ExecuteCall(gatewayServerURL, "hub.login", []interface{}{tc.loginCredentials.username, tc.loginCredentials.password})
+			loginResponse, err := client.ExecuteCall(context.Background(), gatewayServerURL, "hub.login", []interface{}{tc.loginCredentials.username, tc.loginCredentials.password})
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("Error occurred when executing login: %v", err)
 			}
 			hubSessionKey := loginResponse.(string)
 			//logout
-			_, err = client.ExecuteCall(gatewayServerURL, "hub.logout", tc.logoutParametersResolver(hubSessionKey))
+			_, err = client.ExecuteCall(context.Background(), gatewayServerURL, "hub.logout", tc.logoutParametersResolver(hubSessionKey))
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("Error occurred when executing logout: %v", err)
 			}
@@ -1,6 +1,7 @@
 package integration_tests
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -32,14 +33,14 @@ func Test_Multicast(t *testing.T) {
 			//setup env
 			client := client.NewClient(10, 10)
 			//login
-			loginResponse, err := client.ExecuteCall(gatewayServerURL, "hub.loginWithAutoconnectMode", []interface{}{tc.loginCredentials.username, tc.loginCredentials.password})
+			loginResponse, err := client.ExecuteCall(context.Background(), gatewayServerURL, "hub.loginWithAutoconnectMode", []interface{}{tc.loginCredentials.username, tc.loginCredentials.password})
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("Error occurred when executing login: %v", err)
 			}
 			hubSessionKey := loginResponse.(map[string]interface{})["SessionKey"].(string)
 			loggedInServerIDs := getLoggedInServerIDsFromLoginResponse(loginResponse)
 			//execute multicast call
-			multicastResponse, err := client.ExecuteCall(gatewayServerURL, tc.call, []interface{}{hubSessionKey, loggedInServerIDs})
+			multicastResponse, err := client.ExecuteCall(context.Background(), gatewayServerURL, tc.call, []interface{}{hubSessionKey, loggedInServerIDs})
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("Error occurred when executing multicast call: %v", err)
 			}
@@ -47,7 +48,7 @@ func Test_Multicast(t *testing.T) {
 				t.Fatalf("Expected and actual multicast responses don't match. Actual response is: %v", multicastResponse)
 			}
 			//logout
-			_, err = client.ExecuteCall(gatewayServerURL, "hub.logout", []interface{}{hubSessionKey})
+			_, err = client.ExecuteCall(context.Background(), gatewayServerURL, "hub.logout", []interface{}{hubSessionKey})
 			if err != nil && (tc.expectedError == "" || !strings.Contains(err.Error(), tc.expectedError)) {
 				t.Fatalf("Error occurred when executing logout: %v", err)
 			}
@@ -122,7 +123,8 @@ func analizeUnkonwMethodMulticastResponse(multicastResponse interface{}) bool {
 			return false
 		}
 		for _, failedServerResponse := range failedServerResponses {
-			if "request error: bad status code - 400" != failedServerResponse.(string) {
+			failedResponse := failedServerResponse.(map[string]interface{})
+			if !strings.HasSuffix(failedResponse["FaultString"].(string), "request error: bad status code - 400") || failedResponse["ErrorCategory"] != "error" {
 				return false
 			}
 		}
@@ -0,0 +1,191 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
+const (
+	levelInfo  = "info"
+	levelWarn  = "warn"
+	levelError = "error"
+)
+
+//Logger writes structured entries, one per line, in JSON or logfmt format.
+//Every entry includes the ID of the request being served, if any.
+type Logger struct {
+	mutex  sync.Mutex
+	output io.Writer
+	format string
+}
+
+//NewLogger instantiates a Logger writing to output in the given format
+func NewLogger(output io.Writer, format string) (*Logger, error) {
+	switch format {
+	case FormatJSON, FormatLogfmt:
+		return &Logger{output: output, format: format}, nil
+	}
+	return nil, fmt.Errorf("unknown log format: %v", format)
+}
+
+var (
+	defaultLoggerMutex sync.RWMutex
+	defaultLogger      = &Logger{output: os.Stderr, format: FormatLogfmt}
+)
+
+//SetLogger makes logger the one used by the package level functions.
+//The output of the standard log package is redirected to it as well.
+func SetLogger(logger *Logger) {
+	defaultLoggerMutex.Lock()
+	defaultLogger = logger
+	defaultLoggerMutex.Unlock()
+	log.SetFlags(0)
+	log.SetOutput(stdLogWriter{})
+}
+
+func getLogger() *Logger {
+	defaultLoggerMutex.RLock()
+	defer defaultLoggerMutex.RUnlock()
+	return defaultLogger
+}
+
+//Info logs an informational message. keyValues are alternating keys and values added to the entry
+func Info(ctx context.Context, msg string, keyValues ...interface{}) {
+	getLogger().Log(ctx, levelInfo, msg, keyValues...)
+}
+
+//Warn logs a message about an unexpected condition the request recovered from
+func Warn(ctx context.Context, msg string, keyValues ...interface{}) {
+	getLogger().Log(ctx, levelWarn, msg, keyValues...)
+}
+
+//Error logs a message about a failure
+func Error(ctx context.Context, msg string, keyValues ...interface{}) {
+	getLogger().Log(ctx, levelError, msg, keyValues...)
+}
+
+//Log writes an entry with the given level. Session keys found in keyValues are redacted
+func (l *Logger) Log(ctx context.Context, level, msg string, keyValues ...interface{}) {
+	fields := []field{{"time", time.Now().UTC().Format(time.RFC3339Nano)}, {"level", level}}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields, field{"request_id", requestID})
+	}
+	fields = append(fields, field{"msg", msg})
+	for i := 0; i < len(keyValues); i += 2 {
+		key := fmt.Sprint(keyValues[i])
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keyValues) {
+			value = keyValues[i+1]
+		}
+		fields = append(fields, field{key, redact(key, value)})
+	}
+
+	var line bytes.Buffer
+	if l.format == FormatJSON {
+		writeJSON(&line, fields)
+	} else {
+		writeLogfmt(&line, fields)
+	}
+	line.WriteByte('\n')
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.output.Write(line.Bytes())
+}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+func writeJSON(w *bytes.Buffer, fields []field) {
+	w.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.key)
+		w.Write(key)
+		w.WriteByte(':')
+		value, err := json.Marshal(jsonValue(f.value))
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprint(f.value))
+		}
+		w.Write(value)
+	}
+	w.WriteByte('}')
+}
+
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return value
+}
+
+func writeLogfmt(w *bytes.Buffer, fields []field) {
+	for i, f := range fields {
+		if i > 0 {
+			w.WriteByte(' ')
+		}
+		w.WriteString(f.key)
+		w.WriteByte('=')
+		w.WriteString(logfmtValue(fmt.Sprint(f.value)))
+	}
+}
+
+func logfmtValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " =\"\\\t\r\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+//redact hides the values of the fields holding session keys or passwords
+func redact(key string, value interface{}) interface{} {
+	lowerKey := strings.ToLower(key)
+	switch {
+	case strings.HasSuffix(lowerKey, "session_key"):
+		return RedactSessionKey(fmt.Sprint(value))
+	case strings.Contains(lowerKey, "password"):
+		return "[REDACTED]"
+	}
+	return value
+}
+
+//RedactSessionKey returns an identifier of the session key which can be used to correlate log entries
+//without disclosing the key itself
+func RedactSessionKey(sessionKey string) string {
+	if sessionKey == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(sessionKey))
+	return "sha256:" + hex.EncodeToString(hash[:])[:12]
+}
+
+//stdLogWriter turns every line written through the standard log package into an informational entry
+type stdLogWriter struct{}
+
+func (stdLogWriter) Write(p []byte) (int, error) {
+	Info(context.Background(), strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func Test_Log(t *testing.T) {
+	tt := []struct {
+		name          string
+		format        string
+		ctx           context.Context
+		msg           string
+		keyValues     []interface{}
+		expectedEntry string
+	}{
+		{
+			name:          "Log logfmt",
+			format:        FormatLogfmt,
+			ctx:           WithRequestID(context.Background(), "requestID"),
+			msg:           "Call error",
+			keyValues:     []interface{}{"server_id", int64(1000010000), "error", errors.New("request timeout: i/o timeout")},
+			expectedEntry: `level=error request_id=requestID msg="Call error" server_id=1000010000 error="request timeout: i/o timeout"`,
+		},
+		{
+			name:          "Log logfmt without_request_id",
+			format:        FormatLogfmt,
+			ctx:           context.Background(),
+			msg:           "Restored",
+			keyValues:     []interface{}{"count", 2, "path", ""},
+			expectedEntry: `level=error msg=Restored count=2 path=""`,
+		},
+		{
+			name:          "Log logfmt session_keys_redacted",
+			format:        FormatLogfmt,
+			ctx:           context.Background(),
+			msg:           "HubSession was not found",
+			keyValues:     []interface{}{"hub_session_key", "hubSessionKey", "password", "secret"},
+			expectedEntry: `level=error msg="HubSession was not found" hub_session_key=` + RedactSessionKey("hubSessionKey") + ` password=[REDACTED]`,
+		},
+		{
+			name:          "Log json",
+			format:        FormatJSON,
+			ctx:           WithRequestID(context.Background(), "requestID"),
+			msg:           "Call error",
+			keyValues:     []interface{}{"server_id", int64(1000010000), "hub_session_key", "hubSessionKey", "error", errors.New("call_error")},
+			expectedEntry: `"level":"error","request_id":"requestID","msg":"Call error","server_id":1000010000,"hub_session_key":"` + RedactSessionKey("hubSessionKey") + `","error":"call_error"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var output bytes.Buffer
+			logger, err := NewLogger(&output, tc.format)
+			if err != nil {
+				t.Fatalf("Error during logger creation: %v", err)
+			}
+
+			logger.Log(tc.ctx, levelError, tc.msg, tc.keyValues...)
+
+			entry := output.String()
+			if !strings.HasSuffix(entry, tc.expectedEntry+"\n") {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", entry, tc.expectedEntry)
+			}
+			if strings.Contains(entry, "hubSessionKey") || strings.Contains(entry, "secret") {
+				t.Fatalf("sensitive value was not redacted: %v", entry)
+			}
+			if tc.format == FormatJSON && !json.Valid(output.Bytes()) {
+				t.Fatalf("invalid JSON entry: %v", entry)
+			}
+		})
+	}
+}
+
+func Test_NewLogger_unknown_format(t *testing.T) {
+	if _, err := NewLogger(&bytes.Buffer{}, "xml"); err == nil {
+		t.Fatalf("expected error for unknown log format")
+	}
+}
+
+func Test_RequestIDHandler(t *testing.T) {
+	tt := []struct {
+		name              string
+		requestID         string
+		expectedRequestID *regexp.Regexp
+	}{
+		{
+			name:              "RequestIDHandler generated",
+			expectedRequestID: regexp.MustCompile(`^[0-9a-f]{16}$`),
+		},
+		{
+			name:              "RequestIDHandler sent_by_caller",
+			requestID:         "caller-request.1",
+			expectedRequestID: regexp.MustCompile(`^caller-request\.1$`),
+		},
+		{
+			name:              "RequestIDHandler invalid_sent_by_caller",
+			requestID:         "invalid request id",
+			expectedRequestID: regexp.MustCompile(`^[0-9a-f]{16}$`),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var requestID string
+			handler := RequestIDHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestID = RequestIDFromContext(r.Context())
+			}))
+			request := httptest.NewRequest("POST", "/hub/rpc/api", nil)
+			if tc.requestID != "" {
+				request.Header.Set(RequestIDHeader, tc.requestID)
+			}
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			if !tc.expectedRequestID.MatchString(requestID) {
+				t.Fatalf("unexpected request ID: %v", requestID)
+			}
+			if recorder.Header().Get(RequestIDHeader) != requestID {
+				t.Fatalf("expected and actual don't match. Actual was:  %v. Expected was: %v", recorder.Header().Get(RequestIDHeader), requestID)
+			}
+		})
+	}
+}
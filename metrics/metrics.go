@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+//latencyBuckets covers from quick calls to the long ones on servers with many clients, in seconds
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+var (
+	requestsTotal = NewCounterVec("hub_xmlrpc_api_requests_total",
+		"Number of requests served, by namespace, method and outcome.", "namespace", "method", "outcome")
+	requestDuration = NewHistogramVec("hub_xmlrpc_api_request_duration_seconds",
+		"Time spent serving requests, by namespace and method.", latencyBuckets, "namespace", "method")
+	serverCallsTotal = NewCounterVec("hub_xmlrpc_api_server_calls_total",
+		"Number of calls executed on peripheral servers, by server ID and outcome.", "server_id", "outcome")
+	serverCallDuration = NewHistogramVec("hub_xmlrpc_api_server_call_duration_seconds",
+		"Time spent calling peripheral servers, by server ID.", latencyBuckets, "server_id")
+	inFlightCalls = NewGauge("hub_xmlrpc_api_in_flight_outbound_calls",
+		"Number of calls to the Hub and to peripheral servers currently being executed.")
+)
+
+//ObserveServerCall records the outcome and the duration of a call to a peripheral server
+func ObserveServerCall(serverID string, success bool, duration time.Duration) {
+	serverCallsTotal.Inc(serverID, outcome(success))
+	serverCallDuration.Observe(duration.Seconds(), serverID)
+}
+
+//OutboundCallStarted must be called when a call to the Hub or to a peripheral server starts,
+//and the returned function when it finishes
+func OutboundCallStarted() func() {
+	inFlightCalls.Inc()
+	return inFlightCalls.Dec
+}
+
+func outcome(success bool) string {
+	if success {
+		return "success"
+	}
+	return "failure"
+}
+
+//requestLabels are filled in by the codec while serving a request
+type requestLabels struct {
+	namespace, method string
+	fault             bool
+}
+
+type requestLabelsKey struct{}
+
+//SetRequestMethod records the namespace and the method of the request being served with ctx
+func SetRequestMethod(ctx context.Context, namespace, method string) {
+	if labels, ok := ctx.Value(requestLabelsKey{}).(*requestLabels); ok {
+		labels.namespace, labels.method = namespace, method
+	}
+}
+
+//SetRequestFault records that the request being served with ctx is answered with a fault
+func SetRequestFault(ctx context.Context) {
+	if labels, ok := ctx.Value(requestLabelsKey{}).(*requestLabels); ok {
+		labels.fault = true
+	}
+}
+
+//InstrumentHandler records the number of requests served by handler and their latency
+func InstrumentHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		labels := &requestLabels{namespace: "unknown", method: "unknown"}
+		statusRecorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		handler.ServeHTTP(statusRecorder, r.WithContext(context.WithValue(r.Context(), requestLabelsKey{}, labels)))
+
+		requestOutcome := "success"
+		if statusRecorder.status != http.StatusOK {
+			requestOutcome = "error"
+		} else if labels.fault {
+			requestOutcome = "fault"
+		}
+		requestsTotal.Inc(labels.namespace, labels.method, requestOutcome)
+		requestDuration.Observe(time.Since(start).Seconds(), labels.namespace, labels.method)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func scrape(t *testing.T) string {
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type: %v", contentType)
+	}
+	return recorder.Body.String()
+}
+
+func Test_Handler(t *testing.T) {
+	counter := NewCounterVec("test_counter_total", "A test counter.", "method")
+	counter.Inc("system.listSystems")
+	counter.Inc("system.listSystems")
+	counter.Inc(`weird"method`)
+
+	histogram := NewHistogramVec("test_histogram_seconds", "A test histogram.", []float64{0.1, 1}, "method")
+	histogram.Observe(0.05, "system.listSystems")
+	histogram.Observe(0.5, "system.listSystems")
+	histogram.Observe(5, "system.listSystems")
+
+	gauge := NewGauge("test_gauge", "A test gauge.")
+	gauge.Inc()
+	gauge.Inc()
+	gauge.Dec()
+
+	NewGaugeFunc("test_gauge_func", "A test gauge\nfunction.", func() float64 { return 42 })
+
+	output := scrape(t)
+
+	tt := []struct {
+		name          string
+		expectedLines []string
+	}{
+		{
+			name: "counter",
+			expectedLines: []string{
+				"# HELP test_counter_total A test counter.",
+				"# TYPE test_counter_total counter",
+				`test_counter_total{method="system.listSystems"} 2`,
+				`test_counter_total{method="weird\"method"} 1`,
+			},
+		},
+		{
+			name: "histogram",
+			expectedLines: []string{
+				"# TYPE test_histogram_seconds histogram",
+				`test_histogram_seconds_bucket{method="system.listSystems",le="0.1"} 1`,
+				`test_histogram_seconds_bucket{method="system.listSystems",le="1"} 2`,
+				`test_histogram_seconds_bucket{method="system.listSystems",le="+Inf"} 3`,
+				`test_histogram_seconds_sum{method="system.listSystems"} 5.55`,
+				`test_histogram_seconds_count{method="system.listSystems"} 3`,
+			},
+		},
+		{
+			name: "gauge",
+			expectedLines: []string{
+				"# TYPE test_gauge gauge",
+				"test_gauge 1",
+			},
+		},
+		{
+			name: "gauge_func",
+			expectedLines: []string{
+				`# HELP test_gauge_func A test gauge\nfunction.`,
+				"test_gauge_func 42",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, expectedLine := range tc.expectedLines {
+				if !strings.Contains(output, expectedLine+"\n") {
+					t.Fatalf("expected line %q was not found in:\n%v", expectedLine, output)
+				}
+			}
+		})
+	}
+}
+
+func Test_InstrumentHandler(t *testing.T) {
+	tt := []struct {
+		name         string
+		handler      http.HandlerFunc
+		expectedLine string
+	}{
+		{
+			name: "InstrumentHandler success",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				SetRequestMethod(r.Context(), "multicast", "proxied")
+			},
+			expectedLine: `hub_xmlrpc_api_requests_total{namespace="multicast",method="proxied",outcome="success"} 1`,
+		},
+		{
+			name: "InstrumentHandler fault",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				SetRequestMethod(r.Context(), "hub", "hub.login")
+				SetRequestFault(r.Context())
+			},
+			expectedLine: `hub_xmlrpc_api_requests_total{namespace="hub",method="hub.login",outcome="fault"} 1`,
+		},
+		{
+			name: "InstrumentHandler error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			},
+			expectedLine: `hub_xmlrpc_api_requests_total{namespace="unknown",method="unknown",outcome="error"} 1`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			InstrumentHandler(tc.handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/hub/rpc/api", nil))
+
+			if output := scrape(t); !strings.Contains(output, tc.expectedLine+"\n") {
+				t.Fatalf("expected line %q was not found in:\n%v", tc.expectedLine, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//metric is implemented by every kind of metric which can be exposed by a registry
+type metric interface {
+	name() string
+	write(w io.Writer)
+}
+
+//registry holds the metrics exposed in the Prometheus text format
+type registry struct {
+	mutex   sync.Mutex
+	metrics []metric
+}
+
+var defaultRegistry = newRegistry()
+
+func newRegistry() *registry {
+	return &registry{}
+}
+
+func (r *registry) register(m metric) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, registered := range r.metrics {
+		if registered.name() == m.name() {
+			panic("metric registered twice: " + m.name())
+		}
+	}
+	r.metrics = append(r.metrics, m)
+}
+
+func (r *registry) write(w io.Writer) {
+	r.mutex.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mutex.Unlock()
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name() < metrics[j].name() })
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+//Handler serves all the registered metrics in the Prometheus text exposition format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b bytes.Buffer
+		defaultRegistry.write(&b)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(b.Bytes())
+	})
+}
+
+//series holds the values of a metric for a combination of label values
+type series struct {
+	labelValues []string
+	value       float64
+	buckets     []uint64
+	count       uint64
+}
+
+//vec holds the series of a metric, one per combination of label values
+type vec struct {
+	metricName, help, metricType string
+	labelNames                   []string
+	mutex                        sync.Mutex
+	series                       map[string]*series
+}
+
+func newVec(name, help, metricType string, labelNames []string) vec {
+	return vec{metricName: name, help: help, metricType: metricType, labelNames: labelNames, series: make(map[string]*series)}
+}
+
+func (v *vec) name() string {
+	return v.metricName
+}
+
+//seriesFor must be called holding the mutex
+func (v *vec) seriesFor(labelValues []string) *series {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metric %v expects %v label values, got %v", v.metricName, len(v.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		v.series[key] = s
+	}
+	return s
+}
+
+//sortedSeries must be called holding the mutex
+func (v *vec) sortedSeries() []*series {
+	sorted := make([]*series, 0, len(v.series))
+	for _, s := range v.series {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return strings.Join(sorted[i].labelValues, "\xff") < strings.Join(sorted[j].labelValues, "\xff")
+	})
+	return sorted
+}
+
+func (v *vec) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %v %v\n", v.metricName, escapeHelp(v.help))
+	fmt.Fprintf(w, "# TYPE %v %v\n", v.metricName, v.metricType)
+}
+
+//CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	vec
+}
+
+//NewCounterVec registers a new counter with the given label names
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	counter := &CounterVec{newVec(name, help, "counter", labelNames)}
+	defaultRegistry.register(counter)
+	return counter
+}
+
+//Inc increments by one the counter for the given label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.seriesFor(labelValues).value++
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.writeHeader(w)
+	for _, s := range c.sortedSeries() {
+		fmt.Fprintf(w, "%v%v %v\n", c.metricName, formatLabels(c.labelNames, s.labelValues), formatValue(s.value))
+	}
+}
+
+//HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	vec
+	upperBounds []float64
+}
+
+//NewHistogramVec registers a new histogram with the given bucket upper bounds and label names
+func NewHistogramVec(name, help string, upperBounds []float64, labelNames ...string) *HistogramVec {
+	histogram := &HistogramVec{newVec(name, help, "histogram", labelNames), upperBounds}
+	defaultRegistry.register(histogram)
+	return histogram
+}
+
+//Observe adds a value to the histogram for the given label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	s := h.seriesFor(labelValues)
+	if s.buckets == nil {
+		s.buckets = make([]uint64, len(h.upperBounds))
+	}
+	for i, upperBound := range h.upperBounds {
+		if value <= upperBound {
+			s.buckets[i]++
+			break
+		}
+	}
+	s.value += value
+	s.count++
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.writeHeader(w)
+	bucketLabelNames := append(append([]string(nil), h.labelNames...), "le")
+	for _, s := range h.sortedSeries() {
+		var cumulativeCount uint64
+		for i, upperBound := range h.upperBounds {
+			cumulativeCount += s.buckets[i]
+			labels := formatLabels(bucketLabelNames, append(append([]string(nil), s.labelValues...), formatValue(upperBound)))
+			fmt.Fprintf(w, "%v_bucket%v %v\n", h.metricName, labels, cumulativeCount)
+		}
+		labels := formatLabels(bucketLabelNames, append(append([]string(nil), s.labelValues...), "+Inf"))
+		fmt.Fprintf(w, "%v_bucket%v %v\n", h.metricName, labels, s.count)
+		fmt.Fprintf(w, "%v_sum%v %v\n", h.metricName, formatLabels(h.labelNames, s.labelValues), formatValue(s.value))
+		fmt.Fprintf(w, "%v_count%v %v\n", h.metricName, formatLabels(h.labelNames, s.labelValues), s.count)
+	}
+}
+
+//Gauge is a value which can go up and down
+type Gauge struct {
+	vec
+}
+
+//NewGauge registers a new gauge
+func NewGauge(name, help string) *Gauge {
+	gauge := &Gauge{newVec(name, help, "gauge", nil)}
+	defaultRegistry.register(gauge)
+	return gauge
+}
+
+//Add adds delta, which may be negative, to the gauge
+func (g *Gauge) Add(delta float64) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.seriesFor(nil).value += delta
+}
+
+//Inc increments the gauge by one
+func (g *Gauge) Inc() {
+	g.Add(1)
+}
+
+//Dec decrements the gauge by one
+func (g *Gauge) Dec() {
+	g.Add(-1)
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.writeHeader(w)
+	fmt.Fprintf(w, "%v %v\n", g.metricName, formatValue(g.seriesFor(nil).value))
+}
+
+//GaugeFunc is a gauge whose value is computed every time the metrics are collected
+type GaugeFunc struct {
+	vec
+	function func() float64
+}
+
+//NewGaugeFunc registers a new gauge whose value is returned by function
+func NewGaugeFunc(name, help string, function func() float64) *GaugeFunc {
+	gauge := &GaugeFunc{newVec(name, help, "gauge", nil), function}
+	defaultRegistry.register(gauge)
+	return gauge
+}
+
+func (g *GaugeFunc) write(w io.Writer) {
+	g.writeHeader(w)
+	fmt.Fprintf(w, "%v %v\n", g.metricName, formatValue(g.function()))
+}
+
+func formatLabels(labelNames, labelValues []string) string {
+	if len(labelNames) == 0 {
+		return ""
+	}
+	labels := make([]string, 0, len(labelNames))
+	for i, labelName := range labelNames {
+		labels = append(labels, labelName+`="`+escapeLabelValue(labelValues[i])+`"`)
+	}
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
@@ -0,0 +1,236 @@
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
+const encryptionKeySize = 32
+
+//FileSessionStorage persists the sessions held in memory to an AES-GCM encrypted file,
+//so that they survive a restart of the application
+type FileSessionStorage struct {
+	session *sync.Map
+	path    string
+	aead    cipher.AEAD
+	mutex   sync.Mutex
+}
+
+type storedHubSession struct {
+	HubSessionKey, Username, Password string
+	LoginMode                         int
+	CreatedAt                         time.Time
+	ServerSessions                    []storedServerSession
+}
+
+type storedServerSession struct {
+	ServerID                                                              int64
+	ServerAPIEndpoint, ServerSessionKey, AttachmentState, AttachmentError string
+}
+
+//legacyLoginErrorSessionKey is the key previous versions stored for the servers the hub session failed to attach to
+const legacyLoginErrorSessionKey = "login-error"
+
+//NewFileSessionStorage loads the sessions stored in path into syncMap. The encryption key is read from keyPath,
+//and it is generated if it does not exist yet
+func NewFileSessionStorage(syncMap *sync.Map, path, keyPath string) (*FileSessionStorage, error) {
+	key, err := loadOrGenerateEncryptionKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	storage := &FileSessionStorage{session: syncMap, path: path, aead: aead}
+	if err := storage.load(); err != nil {
+		return nil, err
+	}
+	return storage, nil
+}
+
+func loadOrGenerateEncryptionKey(keyPath string) ([]byte, error) {
+	key, err := ioutil.ReadFile(keyPath)
+	if err == nil {
+		if len(key) != encryptionKeySize {
+			return nil, errors.New("Invalid session storage encryption key: unexpected key size")
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	key = make([]byte, encryptionKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomically(keyPath, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *FileSessionStorage) load() error {
+	encrypted, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	nonceSize := s.aead.NonceSize()
+	if len(encrypted) < nonceSize {
+		return errors.New("Invalid session storage file: file is truncated")
+	}
+	plaintext, err := s.aead.Open(nil, encrypted[:nonceSize], encrypted[nonceSize:], nil)
+	if err != nil {
+		return err
+	}
+	var storedHubSessions []storedHubSession
+	if err := json.Unmarshal(plaintext, &storedHubSessions); err != nil {
+		return err
+	}
+	for _, stored := range storedHubSessions {
+		hubSession := gateway.RestoreHubSession(stored.HubSessionKey, stored.Username, stored.Password, stored.LoginMode, stored.CreatedAt)
+		serverSessions := make(map[int64]*gateway.ServerSession, len(stored.ServerSessions))
+		for _, storedServerSession := range stored.ServerSessions {
+			serverSessions[storedServerSession.ServerID] = restoreServerSession(storedServerSession, stored.HubSessionKey)
+		}
+		hubSession.SaveServerSessions(serverSessions)
+		s.session.Store(stored.HubSessionKey, hubSession)
+	}
+	logging.Info(context.Background(), "Restored hub sessions", "count", len(storedHubSessions), "path", s.path)
+	return nil
+}
+
+func restoreServerSession(stored storedServerSession, hubSessionKey string) *gateway.ServerSession {
+	if stored.AttachmentState == gateway.ServerAttachmentStateFailed || stored.ServerSessionKey == legacyLoginErrorSessionKey {
+		attachmentError := stored.AttachmentError
+		if attachmentError == "" {
+			attachmentError = "login to the server failed"
+		}
+		return gateway.NewFailedServerSession(stored.ServerID, stored.ServerAPIEndpoint, attachmentError, hubSessionKey)
+	}
+	return gateway.NewServerSession(stored.ServerID, stored.ServerAPIEndpoint, stored.ServerSessionKey, hubSessionKey)
+}
+
+//Persist writes a snapshot of all the sessions to the storage file
+func (s *FileSessionStorage) Persist() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	storedHubSessions := make([]storedHubSession, 0)
+	s.session.Range(func(key, value interface{}) bool {
+		hubSession := value.(*gateway.HubSession)
+		//the server sessions are copied, as other requests may attach to or detach from servers while they are serialized
+		serverSessionsByID := hubSession.CopyServerSessions()
+		serverSessions := make([]storedServerSession, 0, len(serverSessionsByID))
+		for serverID, serverSession := range serverSessionsByID {
+			serverSessions = append(serverSessions, storedServerSession{serverID, serverSession.ServerAPIEndpoint(), serverSession.ServerSessionKey(),
+				serverSession.AttachmentState(), serverSession.AttachmentError()})
+		}
+		storedHubSessions = append(storedHubSessions, storedHubSession{
+			hubSession.HubSessionKey, hubSession.Username(), hubSession.Password(), hubSession.LoginMode(), hubSession.CreatedAt, serverSessions,
+		})
+		return true
+	})
+
+	plaintext, err := json.Marshal(storedHubSessions)
+	if err != nil {
+		logging.Error(context.Background(), "Error ocurred while serializing sessions", "error", err)
+		return
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		logging.Error(context.Background(), "Error ocurred while encrypting sessions", "error", err)
+		return
+	}
+	if err := writeFileAtomically(s.path, s.aead.Seal(nonce, nonce, plaintext, nil)); err != nil {
+		logging.Error(context.Background(), "Error ocurred while persisting sessions", "path", s.path, "error", err)
+	}
+}
+
+func writeFileAtomically(path string, data []byte) error {
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
+
+//FileHubSessionRepository implements HubSessionRepository, persisting every change through a FileSessionStorage
+type FileHubSessionRepository struct {
+	*InMemoryHubSessionRepository
+	storage *FileSessionStorage
+}
+
+func NewFileHubSessionRepository(storage *FileSessionStorage, ttl, idleTimeout time.Duration) *FileHubSessionRepository {
+	return &FileHubSessionRepository{NewInMemoryHubSessionRepository(storage.session, ttl, idleTimeout), storage}
+}
+
+func (r *FileHubSessionRepository) SaveHubSession(hubSession *gateway.HubSession) {
+	r.InMemoryHubSessionRepository.SaveHubSession(hubSession)
+	r.storage.Persist()
+}
+
+func (r *FileHubSessionRepository) RemoveHubSession(hubSessionKey string) {
+	r.InMemoryHubSessionRepository.RemoveHubSession(hubSessionKey)
+	r.storage.Persist()
+}
+
+func (r *FileHubSessionRepository) RemoveExpiredHubSessions() []*gateway.HubSession {
+	expiredHubSessions := r.InMemoryHubSessionRepository.RemoveExpiredHubSessions()
+	if len(expiredHubSessions) > 0 {
+		r.storage.Persist()
+	}
+	return expiredHubSessions
+}
+
+//FileServerSessionRepository implements ServerSessionRepository, persisting every change through a FileSessionStorage
+type FileServerSessionRepository struct {
+	*InMemoryServerSessionRepository
+	storage *FileSessionStorage
+}
+
+func NewFileServerSessionRepository(storage *FileSessionStorage, ttl, idleTimeout time.Duration) *FileServerSessionRepository {
+	return &FileServerSessionRepository{NewInMemoryServerSessionRepository(storage.session, ttl, idleTimeout), storage}
+}
+
+func (r *FileServerSessionRepository) SaveServerSessions(hubSessionKey string, serverSessions map[int64]*gateway.ServerSession) {
+	r.InMemoryServerSessionRepository.SaveServerSessions(hubSessionKey, serverSessions)
+	r.storage.Persist()
+}
+
+func (r *FileServerSessionRepository) RemoveServerSessions(hubSessionKey string, serverIDs []int64) {
+	r.InMemoryServerSessionRepository.RemoveServerSessions(hubSessionKey, serverIDs)
+	r.storage.Persist()
+}
@@ -0,0 +1,122 @@
+package session
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+)
+
+func TestFileSessionStorageRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hub-sessions")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path, keyPath := filepath.Join(dir, "sessions.db"), filepath.Join(dir, "sessions.key")
+
+	var syncMap sync.Map
+	storage, err := NewFileSessionStorage(&syncMap, path, keyPath)
+	if err != nil {
+		t.Fatalf("Error creating session storage: %v", err)
+	}
+	hubRepo := NewFileHubSessionRepository(storage, 0, 0)
+	serverRepo := NewFileServerSessionRepository(storage, 0, 0)
+
+	hubRepo.SaveHubSession(gateway.NewHubSession("sessionKey", "username", "password", 1))
+	hubRepo.SaveHubSession(gateway.NewHubSession("removedSessionKey", "username", "password", 1))
+	serverRepo.SaveServerSessions("sessionKey", map[int64]*gateway.ServerSession{
+		1234: gateway.NewServerSession(1234, "url", "serverSessionKey", "sessionKey"),
+		5678: gateway.NewFailedServerSession(5678, "url2", "login_error", "sessionKey"),
+	})
+	hubRepo.RemoveHubSession("removedSessionKey")
+
+	encrypted, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading session storage file: %v", err)
+	}
+	if bytes.Contains(encrypted, []byte("password")) || bytes.Contains(encrypted, []byte("serverSessionKey")) {
+		t.Fatalf("session storage file contains secrets in clear text")
+	}
+
+	var restoredSyncMap sync.Map
+	restoredStorage, err := NewFileSessionStorage(&restoredSyncMap, path, keyPath)
+	if err != nil {
+		t.Fatalf("Error restoring session storage: %v", err)
+	}
+	restoredHubRepo := NewFileHubSessionRepository(restoredStorage, 0, 0)
+	restoredServerRepo := NewFileServerSessionRepository(restoredStorage, 0, 0)
+
+	hubSession := restoredHubRepo.RetrieveHubSession("sessionKey")
+	if hubSession == nil || hubSession.Username() != "username" || hubSession.Password() != "password" || hubSession.LoginMode() != 1 {
+		t.Fatalf("HubSession was not restored as expected. Actual is:\n%v", hubSession)
+	}
+	if restoredHubRepo.RetrieveHubSession("removedSessionKey") != nil {
+		t.Fatalf("removed HubSession was restored unexpectedly")
+	}
+	serverSession := restoredServerRepo.RetrieveServerSessionByServerID("sessionKey", 1234)
+	if serverSession == nil || serverSession.ServerAPIEndpoint() != "url" || serverSession.ServerSessionKey() != "serverSessionKey" {
+		t.Fatalf("ServerSession was not restored as expected. Actual is:\n%v", serverSession)
+	}
+	failedServerSession := restoredServerRepo.RetrieveServerSessionByServerID("sessionKey", 5678)
+	if failedServerSession == nil || failedServerSession.ServerAPIEndpoint() != "url2" || failedServerSession.AttachmentError() != "login_error" ||
+		failedServerSession.IsAttached() || failedServerSession.ServerSessionKey() != "" {
+		t.Fatalf("failed ServerSession was not restored as expected. Actual is:\n%v", failedServerSession)
+	}
+}
+
+func TestFileSessionStorageWrongKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hub-sessions")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sessions.db")
+
+	var syncMap sync.Map
+	storage, err := NewFileSessionStorage(&syncMap, path, filepath.Join(dir, "sessions.key"))
+	if err != nil {
+		t.Fatalf("Error creating session storage: %v", err)
+	}
+	NewFileHubSessionRepository(storage, 0, 0).SaveHubSession(gateway.NewHubSession("sessionKey", "username", "password", 1))
+
+	var restoredSyncMap sync.Map
+	if _, err := NewFileSessionStorage(&restoredSyncMap, path, filepath.Join(dir, "other.key")); err == nil {
+		t.Fatalf("expected an error when decrypting the session storage with a different key")
+	}
+}
+
+func TestFileSessionStorageConcurrentSaves(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hub-sessions")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var syncMap sync.Map
+	storage, err := NewFileSessionStorage(&syncMap, filepath.Join(dir, "sessions.db"), filepath.Join(dir, "sessions.key"))
+	if err != nil {
+		t.Fatalf("Error creating session storage: %v", err)
+	}
+	NewFileHubSessionRepository(storage, 0, 0).SaveHubSession(gateway.NewHubSession("sessionKey", "username", "password", 1))
+	serverRepo := NewFileServerSessionRepository(storage, 0, 0)
+
+	//every save persists all the sessions, while the other goroutines keep modifying them
+	var wg sync.WaitGroup
+	for i := int64(1); i <= 10; i++ {
+		wg.Add(1)
+		go func(serverID int64) {
+			defer wg.Done()
+			serverRepo.SaveServerSessions("sessionKey", map[int64]*gateway.ServerSession{serverID: gateway.NewServerSession(serverID, "url", "serverSessionKey", "sessionKey")})
+		}(i)
+	}
+	wg.Wait()
+
+	if serverSessions := serverRepo.RetrieveServerSessions("sessionKey"); len(serverSessions) != 10 {
+		t.Fatalf("expected and actual doesn't match, Actual was: %v, Expected was: %v", len(serverSessions), 10)
+	}
+}
@@ -2,17 +2,19 @@ package session
 
 import (
 	"sync"
+	"time"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
 )
 
 //InMemoryHubSessionRepository implements HubSessionRepository
 type InMemoryHubSessionRepository struct {
-	session *sync.Map
+	session          *sync.Map
+	ttl, idleTimeout time.Duration
 }
 
-func NewInMemoryHubSessionRepository(syncMap *sync.Map) *InMemoryHubSessionRepository {
-	return &InMemoryHubSessionRepository{syncMap}
+func NewInMemoryHubSessionRepository(syncMap *sync.Map, ttl, idleTimeout time.Duration) *InMemoryHubSessionRepository {
+	return &InMemoryHubSessionRepository{syncMap, ttl, idleTimeout}
 }
 
 func (r *InMemoryHubSessionRepository) SaveHubSession(hubSession *gateway.HubSession) {
@@ -20,8 +22,15 @@ func (r *InMemoryHubSessionRepository) SaveHubSession(hubSession *gateway.HubSes
 }
 
 func (s *InMemoryHubSessionRepository) RetrieveHubSession(hubSessionKey string) *gateway.HubSession {
-	if hubSession, ok := s.session.Load(hubSessionKey); ok {
-		return hubSession.(*gateway.HubSession)
+	return loadActiveHubSession(s.session, hubSessionKey, s.ttl, s.idleTimeout)
+}
+
+//PeekHubSession returns the stored hub session if it is not expired, without refreshing its idle timer
+func (s *InMemoryHubSessionRepository) PeekHubSession(hubSessionKey string) *gateway.HubSession {
+	if value, ok := s.session.Load(hubSessionKey); ok {
+		if hubSession := value.(*gateway.HubSession); !hubSession.IsExpired(s.ttl, s.idleTimeout) {
+			return hubSession
+		}
 	}
 	return nil
 }
@@ -30,35 +39,90 @@ func (s *InMemoryHubSessionRepository) RemoveHubSession(hubSessionKey string) {
 	s.session.Delete(hubSessionKey)
 }
 
+func (s *InMemoryHubSessionRepository) RemoveExpiredHubSessions() []*gateway.HubSession {
+	expiredHubSessions := make([]*gateway.HubSession, 0)
+	s.session.Range(func(key, value interface{}) bool {
+		hubSession := value.(*gateway.HubSession)
+		if hubSession.IsExpired(s.ttl, s.idleTimeout) {
+			s.session.Delete(key)
+			expiredHubSessions = append(expiredHubSessions, hubSession)
+		}
+		return true
+	})
+	return expiredHubSessions
+}
+
+//RetrieveHubSessions returns all the stored hub sessions which are not expired, without refreshing their idle timers
+func (s *InMemoryHubSessionRepository) RetrieveHubSessions() []*gateway.HubSession {
+	hubSessions := make([]*gateway.HubSession, 0)
+	s.session.Range(func(key, value interface{}) bool {
+		if hubSession := value.(*gateway.HubSession); !hubSession.IsExpired(s.ttl, s.idleTimeout) {
+			hubSessions = append(hubSessions, hubSession)
+		}
+		return true
+	})
+	return hubSessions
+}
+
+//CountHubSessions returns the number of stored hub sessions which are not expired
+func (s *InMemoryHubSessionRepository) CountHubSessions() int {
+	count := 0
+	s.session.Range(func(key, value interface{}) bool {
+		if !value.(*gateway.HubSession).IsExpired(s.ttl, s.idleTimeout) {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
 //InMemoryServerSessionRepository implements ServerSessionRepository
 type InMemoryServerSessionRepository struct {
-	session *sync.Map
+	session          *sync.Map
+	ttl, idleTimeout time.Duration
 }
 
-func NewInMemoryServerSessionRepository(syncMap *sync.Map) *InMemoryServerSessionRepository {
-	return &InMemoryServerSessionRepository{syncMap}
+func NewInMemoryServerSessionRepository(syncMap *sync.Map, ttl, idleTimeout time.Duration) *InMemoryServerSessionRepository {
+	return &InMemoryServerSessionRepository{syncMap, ttl, idleTimeout}
 }
 
 func (s *InMemoryServerSessionRepository) SaveServerSessions(hubSessionKey string, serverSessions map[int64]*gateway.ServerSession) {
 	if hubSession, ok := s.session.Load(hubSessionKey); ok {
-		for serverID, serverSession := range serverSessions {
-			hubSession.(*gateway.HubSession).ServerSessions[serverID] = serverSession
-		}
+		hubSession.(*gateway.HubSession).SaveServerSessions(serverSessions)
 	}
 }
 
-func (s *InMemoryServerSessionRepository) RetrieveServerSessions(hubSessionKey string) map[int64]*gateway.ServerSession {
+//RemoveServerSessions detaches the given servers from the HubSession
+func (s *InMemoryServerSessionRepository) RemoveServerSessions(hubSessionKey string, serverIDs []int64) {
 	if hubSession, ok := s.session.Load(hubSessionKey); ok {
-		return hubSession.(*gateway.HubSession).ServerSessions
+		hubSession.(*gateway.HubSession).RemoveServerSessions(serverIDs)
+	}
+}
+
+func (s *InMemoryServerSessionRepository) RetrieveServerSessions(hubSessionKey string) map[int64]*gateway.ServerSession {
+	if hubSession := loadActiveHubSession(s.session, hubSessionKey, s.ttl, s.idleTimeout); hubSession != nil {
+		return hubSession.CopyServerSessions()
 	}
 	return make(map[int64]*gateway.ServerSession)
 }
 
 func (s *InMemoryServerSessionRepository) RetrieveServerSessionByServerID(hubSessionKey string, serverID int64) *gateway.ServerSession {
-	if hubSession, ok := s.session.Load(hubSessionKey); ok {
-		if serverSession, ok := hubSession.(*gateway.HubSession).ServerSessions[serverID]; ok {
-			return serverSession
+	if hubSession := loadActiveHubSession(s.session, hubSessionKey, s.ttl, s.idleTimeout); hubSession != nil {
+		return hubSession.ServerSession(serverID)
+	}
+	return nil
+}
+
+//loadActiveHubSession returns the stored HubSession refreshing its idle timer, or nil if it is missing or expired.
+//Expired sessions are left in place so they can be logged out by the HubSessionReaper.
+func loadActiveHubSession(syncMap *sync.Map, hubSessionKey string, ttl, idleTimeout time.Duration) *gateway.HubSession {
+	if value, ok := syncMap.Load(hubSessionKey); ok {
+		hubSession := value.(*gateway.HubSession)
+		if hubSession.IsExpired(ttl, idleTimeout) {
+			return nil
 		}
+		hubSession.Touch()
+		return hubSession
 	}
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
 )
@@ -23,7 +24,7 @@ func TestSaveHubSession(t *testing.T) {
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			var syncMap sync.Map
-			repo := NewInMemoryHubSessionRepository(&syncMap)
+			repo := NewInMemoryHubSessionRepository(&syncMap, 0, 0)
 
 			repo.SaveHubSession(tc.hubSession)
 			hubSession := repo.RetrieveHubSession(tc.hubSessionKey)
@@ -36,6 +37,8 @@ func TestSaveHubSession(t *testing.T) {
 }
 
 func TestRetrieveHubSession(t *testing.T) {
+	hubSession := gateway.NewHubSession("sessionKey", "username", "password", 1)
+
 	tt := []struct {
 		name                   string
 		hubSessionToSave       *gateway.HubSession
@@ -43,9 +46,9 @@ func TestRetrieveHubSession(t *testing.T) {
 		expectedHubSession     *gateway.HubSession
 	}{
 		{name: "RetrieveHubSession Success",
-			hubSessionToSave:       gateway.NewHubSession("sessionKey", "username", "password", 1),
+			hubSessionToSave:       hubSession,
 			hubSessionKeyToLookfor: "sessionKey",
-			expectedHubSession:     gateway.NewHubSession("sessionKey", "username", "password", 1),
+			expectedHubSession:     hubSession,
 		},
 		{name: "RetrieveHubSession inexistent_hubSession_key",
 			hubSessionToSave:       gateway.NewHubSession("sessionKey", "username", "password", 1),
@@ -56,7 +59,7 @@ func TestRetrieveHubSession(t *testing.T) {
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			var syncMap sync.Map
-			repo := NewInMemoryHubSessionRepository(&syncMap)
+			repo := NewInMemoryHubSessionRepository(&syncMap, 0, 0)
 
 			repo.SaveHubSession(tc.hubSessionToSave)
 
@@ -88,10 +91,10 @@ func TestSaveServerSessions(t *testing.T) {
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			var syncMap sync.Map
-			hubRepo := NewInMemoryHubSessionRepository(&syncMap)
+			hubRepo := NewInMemoryHubSessionRepository(&syncMap, 0, 0)
 			hubRepo.SaveHubSession(tc.hubSession)
 
-			repo := NewInMemoryServerSessionRepository(&syncMap)
+			repo := NewInMemoryServerSessionRepository(&syncMap, 0, 0)
 
 			expectedServerSessions := map[int64]*gateway.ServerSession{tc.serverID: tc.serverSession}
 
@@ -106,6 +109,26 @@ func TestSaveServerSessions(t *testing.T) {
 	}
 }
 
+func TestRemoveServerSessions(t *testing.T) {
+	var syncMap sync.Map
+	hubRepo := NewInMemoryHubSessionRepository(&syncMap, 0, 0)
+	hubRepo.SaveHubSession(gateway.NewHubSession("sessionKey", "username", "password", 1))
+
+	repo := NewInMemoryServerSessionRepository(&syncMap, 0, 0)
+	repo.SaveServerSessions("sessionKey", map[int64]*gateway.ServerSession{
+		1: gateway.NewServerSession(1, "url1", "serverSessionKey1", "sessionKey"),
+		2: gateway.NewServerSession(2, "url2", "serverSessionKey2", "sessionKey"),
+	})
+
+	repo.RemoveServerSessions("sessionKey", []int64{1, 3})
+
+	expectedServerSessions := map[int64]*gateway.ServerSession{2: gateway.NewServerSession(2, "url2", "serverSessionKey2", "sessionKey")}
+	serverSessions := repo.RetrieveServerSessions("sessionKey")
+	if !reflect.DeepEqual(serverSessions, expectedServerSessions) {
+		t.Fatalf("expected and actual doesn't match. Expected was:\n%v\nActual is:\n%v", expectedServerSessions, serverSessions)
+	}
+}
+
 func TestRetrieveServerSessionByServerID(t *testing.T) {
 	tt := []struct {
 		name                   string
@@ -149,10 +172,10 @@ func TestRetrieveServerSessionByServerID(t *testing.T) {
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			var syncMap sync.Map
-			hubRepo := NewInMemoryHubSessionRepository(&syncMap)
+			hubRepo := NewInMemoryHubSessionRepository(&syncMap, 0, 0)
 			hubRepo.SaveHubSession(tc.hubSessionToSave)
 
-			repo := NewInMemoryServerSessionRepository(&syncMap)
+			repo := NewInMemoryServerSessionRepository(&syncMap, 0, 0)
 
 			repo.SaveServerSessions(tc.hubSessionKeyToSave, map[int64]*gateway.ServerSession{tc.serverIDToSave: tc.serverSessionToSave})
 
@@ -182,7 +205,7 @@ func TestRemoveHubSession(t *testing.T) {
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			var syncMap sync.Map
-			repo := NewInMemoryHubSessionRepository(&syncMap)
+			repo := NewInMemoryHubSessionRepository(&syncMap, 0, 0)
 
 			repo.SaveHubSession(tc.hubSession)
 			hubSession := repo.RetrieveHubSession(tc.hubSessionKeyToSave)
@@ -201,3 +224,112 @@ func TestRemoveHubSession(t *testing.T) {
 		})
 	}
 }
+
+func TestRetrieveHubSessionWithExpiration(t *testing.T) {
+	tt := []struct {
+		name             string
+		ttl, idleTimeout time.Duration
+		createdAgo       time.Duration
+		idleFor          time.Duration
+		expectedFound    bool
+	}{
+		{name: "RetrieveHubSession active_session",
+			ttl:           time.Hour,
+			idleTimeout:   time.Minute,
+			expectedFound: true,
+		},
+		{name: "RetrieveHubSession ttl_expired",
+			ttl:           time.Hour,
+			idleTimeout:   time.Minute,
+			createdAgo:    2 * time.Hour,
+			expectedFound: false,
+		},
+		{name: "RetrieveHubSession idle_timeout_expired",
+			ttl:           time.Hour,
+			idleTimeout:   5 * time.Millisecond,
+			idleFor:       20 * time.Millisecond,
+			expectedFound: false,
+		},
+		{name: "RetrieveHubSession expiration_disabled",
+			createdAgo:    2 * time.Hour,
+			idleFor:       20 * time.Millisecond,
+			expectedFound: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var syncMap sync.Map
+			repo := NewInMemoryHubSessionRepository(&syncMap, tc.ttl, tc.idleTimeout)
+
+			hubSession := gateway.NewHubSession("sessionKey", "username", "password", 1)
+			hubSession.CreatedAt = hubSession.CreatedAt.Add(-tc.createdAgo)
+			repo.SaveHubSession(hubSession)
+			time.Sleep(tc.idleFor)
+
+			found := repo.RetrieveHubSession("sessionKey") != nil
+
+			if found != tc.expectedFound {
+				t.Fatalf("expected and actual doesn't match. Expected was:\n%v\nActual is:\n%v", tc.expectedFound, found)
+			}
+		})
+	}
+}
+
+func TestPeekHubSession(t *testing.T) {
+	var syncMap sync.Map
+	repo := NewInMemoryHubSessionRepository(&syncMap, time.Hour, time.Hour)
+
+	hubSession := gateway.NewHubSession("sessionKey", "username", "password", 1)
+	repo.SaveHubSession(hubSession)
+	lastAccessedAt := hubSession.LastAccessedAt()
+	time.Sleep(10 * time.Millisecond)
+
+	if peekedHubSession := repo.PeekHubSession("sessionKey"); peekedHubSession != hubSession {
+		t.Fatalf("expected and actual doesn't match. Expected was:\n%v\nActual is:\n%v", hubSession, peekedHubSession)
+	}
+	if !hubSession.LastAccessedAt().Equal(lastAccessedAt) {
+		t.Fatalf("HubSession was touched unexpectedly")
+	}
+	if repo.PeekHubSession("missingSessionKey") != nil {
+		t.Fatalf("expected no HubSession for a missing session key")
+	}
+}
+
+func TestRemoveExpiredHubSessions(t *testing.T) {
+	var syncMap sync.Map
+	repo := NewInMemoryHubSessionRepository(&syncMap, time.Hour, 0)
+
+	activeHubSession := gateway.NewHubSession("activeSessionKey", "username", "password", 1)
+	expiredHubSession := gateway.NewHubSession("expiredSessionKey", "username", "password", 1)
+	expiredHubSession.CreatedAt = expiredHubSession.CreatedAt.Add(-2 * time.Hour)
+	repo.SaveHubSession(activeHubSession)
+	repo.SaveHubSession(expiredHubSession)
+
+	expiredHubSessions := repo.RemoveExpiredHubSessions()
+
+	if !reflect.DeepEqual(expiredHubSessions, []*gateway.HubSession{expiredHubSession}) {
+		t.Fatalf("expected and actual doesn't match. Expected was:\n%v\nActual is:\n%v", []*gateway.HubSession{expiredHubSession}, expiredHubSessions)
+	}
+	if _, ok := syncMap.Load("expiredSessionKey"); ok {
+		t.Fatalf("expired HubSession was not removed as expected")
+	}
+	if repo.RetrieveHubSession("activeSessionKey") != activeHubSession {
+		t.Fatalf("active HubSession was removed unexpectedly")
+	}
+}
+
+func TestCountHubSessions(t *testing.T) {
+	var syncMap sync.Map
+	repo := NewInMemoryHubSessionRepository(&syncMap, time.Hour, 0)
+
+	expiredHubSession := gateway.NewHubSession("expiredSessionKey", "username", "password", 1)
+	expiredHubSession.CreatedAt = expiredHubSession.CreatedAt.Add(-2 * time.Hour)
+	repo.SaveHubSession(gateway.NewHubSession("activeSessionKey1", "username", "password", 1))
+	repo.SaveHubSession(gateway.NewHubSession("activeSessionKey2", "username", "password", 1))
+	repo.SaveHubSession(expiredHubSession)
+
+	if count := repo.CountHubSessions(); count != 2 {
+		t.Fatalf("expected and actual doesn't match. Expected was:\n%v\nActual is:\n%v", 2, count)
+	}
+}
@@ -0,0 +1,111 @@
+package uyuni
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+)
+
+//circuitBreakers keeps a circuit breaker per endpoint. A breaker opens after failureThreshold consecutive
+//transient failures, making the calls to its endpoint fail fast. After openTimeout, a single probe call is let through
+//(half-open state): the breaker closes again if it succeeds, and opens again otherwise.
+type circuitBreakers struct {
+	mutex            sync.Mutex
+	failureThreshold int
+	openTimeout      time.Duration
+	breakers         map[string]*circuitBreaker
+}
+
+type circuitBreaker struct {
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	probing             bool
+}
+
+//NewCircuitBreakers instantiates the circuit breakers for all the endpoints. A failureThreshold of 0 disables them.
+func NewCircuitBreakers(failureThreshold int, openTimeout time.Duration) *circuitBreakers {
+	return &circuitBreakers{failureThreshold: failureThreshold, openTimeout: openTimeout, breakers: make(map[string]*circuitBreaker)}
+}
+
+func (c *circuitBreakers) enabled() bool {
+	return c != nil && c.failureThreshold > 0
+}
+
+func (c *circuitBreakers) breaker(endpoint string) *circuitBreaker {
+	breaker, ok := c.breakers[endpoint]
+	if !ok {
+		breaker = &circuitBreaker{state: gateway.CircuitBreakerClosed}
+		c.breakers[endpoint] = breaker
+	}
+	return breaker
+}
+
+//allow returns an error if calls to the endpoint must not be executed
+func (c *circuitBreakers) allow(endpoint string) error {
+	if !c.enabled() {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	breaker := c.breaker(endpoint)
+	switch breaker.state {
+	case gateway.CircuitBreakerOpen:
+		if time.Since(breaker.openedAt) < c.openTimeout {
+			return fmt.Errorf("%w: circuit breaker is open after %v consecutive failures", gateway.ErrServerUnavailable, breaker.consecutiveFailures)
+		}
+		breaker.state = gateway.CircuitBreakerHalfOpen
+		breaker.probing = true
+	case gateway.CircuitBreakerHalfOpen:
+		if breaker.probing {
+			return fmt.Errorf("%w: circuit breaker is half-open, waiting for the server to respond", gateway.ErrServerUnavailable)
+		}
+		breaker.probing = true
+	}
+	return nil
+}
+
+//recordResult updates the breaker of the endpoint with the outcome of a call. Only transient errors count as failures,
+//as any other error means the server was able to respond.
+func (c *circuitBreakers) recordResult(ctx context.Context, endpoint string, err error) {
+	if !c.enabled() {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	breaker := c.breaker(endpoint)
+	breaker.probing = false
+	switch {
+	case isTransient(err):
+		breaker.consecutiveFailures++
+		if breaker.state == gateway.CircuitBreakerHalfOpen || breaker.consecutiveFailures >= c.failureThreshold {
+			breaker.state = gateway.CircuitBreakerOpen
+			breaker.openedAt = time.Now()
+		}
+	case err != nil && ctx.Err() != nil:
+		//the call was abandoned by the caller, so it says nothing about the server
+	default:
+		breaker.state = gateway.CircuitBreakerClosed
+		breaker.consecutiveFailures = 0
+	}
+}
+
+//RetrieveCircuitBreakerState returns the state of the circuit breaker of the given endpoint
+func (c *circuitBreakers) RetrieveCircuitBreakerState(endpoint string) *gateway.CircuitBreakerState {
+	if !c.enabled() {
+		return &gateway.CircuitBreakerState{State: gateway.CircuitBreakerClosed}
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	breaker, ok := c.breakers[endpoint]
+	if !ok {
+		return &gateway.CircuitBreakerState{State: gateway.CircuitBreakerClosed}
+	}
+	return &gateway.CircuitBreakerState{State: breaker.state, ConsecutiveFailures: breaker.consecutiveFailures}
+}
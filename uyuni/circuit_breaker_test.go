@@ -0,0 +1,75 @@
+package uyuni
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+)
+
+func TestCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	circuitBreakers := NewCircuitBreakers(2, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := circuitBreakers.allow("endpoint"); err != nil {
+			t.Fatalf("Unexpected error was returned: %v", err)
+		}
+		circuitBreakers.recordResult(ctx, "endpoint", &mockTransientError{})
+	}
+
+	if err := circuitBreakers.allow("endpoint"); err == nil {
+		t.Fatalf("Expected error was not returned while the circuit breaker is open")
+	}
+	if err := circuitBreakers.allow("other-endpoint"); err != nil {
+		t.Fatalf("Unexpected error was returned for a different endpoint: %v", err)
+	}
+	state := circuitBreakers.RetrieveCircuitBreakerState("endpoint")
+	if state.State != gateway.CircuitBreakerOpen || state.ConsecutiveFailures != 2 {
+		t.Fatalf("expected and actual doesn't match, Actual was: %v, Expected was: %v", *state, gateway.CircuitBreakerState{gateway.CircuitBreakerOpen, 2})
+	}
+}
+
+func TestCircuitBreakerIgnoresNonTransientErrors(t *testing.T) {
+	circuitBreakers := NewCircuitBreakers(1, time.Minute)
+
+	circuitBreakers.recordResult(context.Background(), "endpoint", errors.New("Fault(2950): Either the password or username is incorrect"))
+
+	if err := circuitBreakers.allow("endpoint"); err != nil {
+		t.Fatalf("Unexpected error was returned: %v", err)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tt := []struct {
+		name          string
+		probeErr      error
+		expectedState string
+	}{
+		{name: "successful probe closes the breaker", probeErr: nil, expectedState: gateway.CircuitBreakerClosed},
+		{name: "failed probe opens the breaker again", probeErr: &mockTransientError{}, expectedState: gateway.CircuitBreakerOpen},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			circuitBreakers := NewCircuitBreakers(1, 10*time.Millisecond)
+			ctx := context.Background()
+			circuitBreakers.recordResult(ctx, "endpoint", &mockTransientError{})
+			time.Sleep(20 * time.Millisecond)
+
+			if err := circuitBreakers.allow("endpoint"); err != nil {
+				t.Fatalf("Unexpected error was returned for the probe call: %v", err)
+			}
+			if err := circuitBreakers.allow("endpoint"); err == nil {
+				t.Fatalf("Expected error was not returned while the probe call is in progress")
+			}
+			circuitBreakers.recordResult(ctx, "endpoint", tc.probeErr)
+
+			if state := circuitBreakers.RetrieveCircuitBreakerState("endpoint"); state.State != tc.expectedState {
+				t.Fatalf("expected and actual doesn't match, Actual was: %v, Expected was: %v", state.State, tc.expectedState)
+			}
+		})
+	}
+}
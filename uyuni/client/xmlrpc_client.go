@@ -2,45 +2,240 @@ package client
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net"
 	"net/http"
+	"net/rpc"
+	"regexp"
+	"strconv"
 	"time"
 
+	"github.com/uyuni-project/hub-xmlrpc-api/metrics"
 	xmlrpc "github.com/uyuni-project/xmlrpc-public-methods"
 )
 
+const (
+	defaultMaxIdleConns          = 100
+	defaultMaxIdleConnsPerServer = 2
+	defaultIdleConnTimeout       = 90
+)
+
+var errRequestTimeout = errors.New("request timeout: i/o timeout")
+
+//faultRx matches the errors the xmlrpc library returns for faults, which only keep the text of the fault
+var faultRx = regexp.MustCompile(`(?s)^Fault\((-?\d+)\): (.*)$`)
+
+//FaultError is returned when the server answers the call with a fault
+type FaultError struct {
+	Code   int
+	String string
+}
+
+func (e *FaultError) Error() string {
+	return "Fault(" + strconv.Itoa(e.Code) + "): " + e.String
+}
+
+//FaultCode returns the code of the fault returned by the server
+func (e *FaultError) FaultCode() int {
+	return e.Code
+}
+
+//FaultString returns the message of the fault returned by the server
+func (e *FaultError) FaultString() string {
+	return e.String
+}
+
+func toFaultError(err error) (*FaultError, bool) {
+	serverErr, ok := err.(rpc.ServerError)
+	if !ok {
+		return nil, false
+	}
+	match := faultRx.FindStringSubmatch(string(serverErr))
+	if match == nil {
+		return nil, false
+	}
+	code, err := strconv.Atoi(match[1])
+	if err != nil {
+		return nil, false
+	}
+	return &FaultError{code, match[2]}, true
+}
+
+//TransientError wraps the errors caused by network failures or HTTP server errors,
+//after which the same call may succeed if it is retried
+type TransientError struct {
+	Err     error
+	timeout bool
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+//Transient reports whether the call failed because of a transient condition
+func (e *TransientError) Transient() bool {
+	return true
+}
+
+//Timeout reports whether the call failed because the server did not answer in time
+func (e *TransientError) Timeout() bool {
+	return e.timeout
+}
+
+//Client executes XMLRPC calls reusing HTTP connections through a transport shared by all the calls.
+//The transport keeps a pool of idle connections per endpoint.
 type Client struct {
-	connectTimeout, requestTimeout int
+	transport      *pooledTransport
+	requestTimeout time.Duration
+}
+
+type callTimeouts struct {
+	connectTimeout, requestTimeout time.Duration
+}
+
+type callTimeoutsKey struct{}
+
+//WithTimeouts returns a copy of ctx overriding the connect and request timeouts of the Client
+//for the calls executed with it. A zero timeout keeps the one of the Client
+func WithTimeouts(ctx context.Context, connectTimeout, requestTimeout time.Duration) context.Context {
+	return context.WithValue(ctx, callTimeoutsKey{}, callTimeouts{connectTimeout, requestTimeout})
 }
 
+func timeoutsFromContext(ctx context.Context) callTimeouts {
+	timeouts, _ := ctx.Value(callTimeoutsKey{}).(callTimeouts)
+	return timeouts
+}
+
+//NewClient instantiates a Client with the default connection pool settings
 func NewClient(connectTimeout, requestTimeout int) *Client {
-	return &Client{connectTimeout: connectTimeout, requestTimeout: requestTimeout}
+	return NewClientWithConnectionPool(connectTimeout, requestTimeout, defaultMaxIdleConns, defaultMaxIdleConnsPerServer, defaultIdleConnTimeout)
+}
+
+//NewClientWithConnectionPool instantiates a Client keeping at most maxIdleConns idle connections in total,
+//maxIdleConnsPerServer idle connections per endpoint, each of them for at most idleConnTimeout seconds
+func NewClientWithConnectionPool(connectTimeout, requestTimeout, maxIdleConns, maxIdleConnsPerServer, idleConnTimeout int) *Client {
+	transport := &http.Transport{
+		DialContext:         dialContext(time.Duration(connectTimeout) * time.Second),
+		TLSHandshakeTimeout: time.Duration(connectTimeout) * time.Second,
+		MaxIdleConns:        maxIdleConns,
+		MaxIdleConnsPerHost: maxIdleConnsPerServer,
+		IdleConnTimeout:     time.Duration(idleConnTimeout) * time.Second,
+	}
+	return &Client{&pooledTransport{transport}, time.Duration(requestTimeout) * time.Second}
 }
 
-func (c *Client) ExecuteCall(endpoint string, call string, args []interface{}) (response interface{}, err error) {
-	client, err := getClientWithTimeout(endpoint, c.connectTimeout, c.requestTimeout)
+//ExecuteCall calls the method on the given endpoint. The call is aborted as soon as ctx is done.
+//Failures at the network or HTTP level are returned as a TransientError and faults as a FaultError
+func (c *Client) ExecuteCall(ctx context.Context, endpoint string, call string, args []interface{}) (response interface{}, err error) {
+	requestTimeout := c.requestTimeout
+	if timeouts := timeoutsFromContext(ctx); timeouts.requestTimeout > 0 {
+		requestTimeout = timeouts.requestTimeout
+	}
+	transport := &requestTimeoutTransport{ctx: ctx, transport: c.transport, requestTimeout: requestTimeout}
+	client, err := xmlrpc.NewClient(endpoint, transport)
 	if err != nil {
 		return nil, err
 	}
 	defer client.Close()
+	defer metrics.OutboundCallStarted()()
 	err = client.Call(call, args, &response)
+	if fault, ok := toFaultError(err); ok {
+		err = fault
+	} else if err != nil && transport.failed && ctx.Err() == nil {
+		err = &TransientError{err, transport.timedOut}
+	}
 	return response, err
 }
 
-func timeoutDialer(connectTimeout, requestTimeout time.Duration) func(ctx context.Context, net, addr string) (c net.Conn, err error) {
-	return func(ctx context.Context, netw, addr string) (net.Conn, error) {
-		conn, err := net.DialTimeout(netw, addr, connectTimeout)
-		if err != nil {
-			return nil, err
+//CloseIdleConnections closes all the connections in the pool which are not in use
+func (c *Client) CloseIdleConnections() {
+	c.transport.transport.CloseIdleConnections()
+}
+
+//dialContext opens connections giving up after connectTimeout, unless the context of the request overrides it
+func dialContext(connectTimeout time.Duration) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		timeout := connectTimeout
+		if timeouts := timeoutsFromContext(ctx); timeouts.connectTimeout > 0 {
+			timeout = timeouts.connectTimeout
 		}
-		conn.SetDeadline(time.Now().Add(requestTimeout))
-		return conn, nil
+		return (&net.Dialer{Timeout: timeout}).DialContext(ctx, network, address)
+	}
+}
+
+//pooledTransport hides the shared http.Transport from the xmlrpc library,
+//which would otherwise close all its idle connections every time a client is closed
+type pooledTransport struct {
+	transport *http.Transport
+}
+
+func (t *pooledTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	return t.transport.RoundTrip(request)
+}
+
+//requestTimeoutTransport binds the requests to the context of the call, and bounds the time spent on them,
+//from sending the request until its response body is read.
+//It also records whether the request failed at the network or HTTP level, and whether it was because of a timeout.
+type requestTimeoutTransport struct {
+	ctx            context.Context
+	transport      http.RoundTripper
+	requestTimeout time.Duration
+	failed         bool
+	timedOut       bool
+}
+
+func (t *requestTimeoutTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(t.ctx, t.requestTimeout)
+	response, err := t.transport.RoundTrip(request.WithContext(ctx))
+	if err != nil {
+		//the error must be translated before cancelling, so it is not mistaken for a cancellation of the call
+		err = translateTimeoutError(ctx, err)
+		cancel()
+		t.failed = true
+		t.timedOut = isTimeoutError(err)
+		return nil, err
+	}
+	if response.StatusCode >= http.StatusInternalServerError {
+		t.failed = true
 	}
+	response.Body = &requestTimeoutBody{response.Body, ctx, cancel, t}
+	return response, nil
+}
+
+type requestTimeoutBody struct {
+	body      io.ReadCloser
+	ctx       context.Context
+	cancel    context.CancelFunc
+	transport *requestTimeoutTransport
 }
 
-func getClientWithTimeout(url string, connectTimeout, requestTimeout int) (*xmlrpc.Client, error) {
-	transport := http.Transport{
-		DialContext: timeoutDialer(time.Duration(connectTimeout)*time.Second, time.Duration(requestTimeout)*time.Second),
+func (b *requestTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if err != nil && err != io.EOF {
+		b.transport.failed = true
+		err = translateTimeoutError(b.ctx, err)
+		b.transport.timedOut = isTimeoutError(err)
 	}
-	return xmlrpc.NewClient(url, &transport)
+	return n, err
+}
+
+func (b *requestTimeoutBody) Close() error {
+	defer b.cancel()
+	return b.body.Close()
+}
+
+func translateTimeoutError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return errRequestTimeout
+	case context.Canceled:
+		return ctx.Err()
+	}
+	return err
+}
+
+func isTimeoutError(err error) bool {
+	var netErr net.Error
+	return err == errRequestTimeout || errors.As(err, &netErr) && netErr.Timeout()
 }
@@ -1,12 +1,15 @@
 package client
 
 import (
+	"context"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -75,7 +78,7 @@ func TestExecuteCall(t *testing.T) {
 			//init client
 			client := NewClient(tc.connectTimeout, tc.requestTimeout)
 
-			response, err := client.ExecuteCall(tc.url, tc.methodName, tc.args)
+			response, err := client.ExecuteCall(context.Background(), tc.url, tc.methodName, tc.args)
 
 			if err != nil && !strings.Contains(err.Error(), tc.expectedError) {
 				t.Fatalf("expected and actual doesn't match, Expected was: %v", tc.expectedError)
@@ -123,13 +126,16 @@ func TestExecuteCallWithTimeout(t *testing.T) {
 
 			//init client
 			client := NewClient(tc.connectTimeout, tc.requestTimeout)
-			response, err := client.ExecuteCall(ts.URL, "test", []interface{}{})
+			response, err := client.ExecuteCall(context.Background(), ts.URL, "test", []interface{}{})
 
 			//We expect error
 			if len(tc.expectedError) > 0 {
 				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
 					t.Fatalf("expected and actual doesn't match, Actuval was: %v, Expected was: %v", err, tc.expectedError)
 				}
+				if transientErr, ok := err.(*TransientError); !ok || !transientErr.Timeout() {
+					t.Fatalf("expected a timeout error, Actual was: %#v", err)
+				}
 
 			} else {
 				//We don't expect error
@@ -145,3 +151,182 @@ func TestExecuteCallWithTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteCallReusesConnections(t *testing.T) {
+	var mutex sync.Mutex
+	newConnections := 0
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, sampleResponse)
+	}))
+	ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mutex.Lock()
+			newConnections++
+			mutex.Unlock()
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	client := NewClient(1, 1)
+	for i := 0; i < 3; i++ {
+		if _, err := client.ExecuteCall(context.Background(), ts.URL, "test", []interface{}{}); err != nil {
+			t.Fatalf("Unexpected error was returned: %v", err.Error())
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if newConnections != 1 {
+		t.Fatalf("expected and actual doesn't match, Actual was: %v, Expected was: %v", newConnections, 1)
+	}
+}
+
+func TestExecuteCallWithCancelledContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		io.WriteString(w, sampleResponse)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	client := NewClient(5, 5)
+	start := time.Now()
+	_, err := client.ExecuteCall(ctx, ts.URL, "test", []interface{}{})
+
+	if err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Fatalf("expected and actual doesn't match, Actual was: %v, Expected was: %v", err, context.Canceled)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("call was not aborted when the context was cancelled")
+	}
+}
+
+func TestExecuteCallConnectionRefused(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	endpoint := ts.URL
+	ts.Close()
+
+	client := NewClient(1, 1)
+	_, err := client.ExecuteCall(context.Background(), endpoint, "test", []interface{}{})
+
+	if err == nil || strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Fatalf("expected a connection error, Actual was: %v", err)
+	}
+	if _, transient := err.(*TransientError); !transient {
+		t.Fatalf("connection error was not reported as transient: %v", err)
+	}
+}
+
+func TestExecuteCallTransientErrors(t *testing.T) {
+	faultResponse := `<?xml version="1.0" encoding="UTF-8"?>
+	<methodResponse><fault><value><struct>
+		<member><name>faultCode</name><value><int>2950</int></value></member>
+		<member><name>faultString</name><value><string>Either the password or username is incorrect.</string></value></member>
+	</struct></value></fault></methodResponse>`
+
+	tt := []struct {
+		name              string
+		handler           http.HandlerFunc
+		expectedTransient bool
+	}{
+		{name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			expectedTransient: true,
+		},
+		{name: "client error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			expectedTransient: false,
+		},
+		{name: "xmlrpc fault",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, faultResponse)
+			},
+			expectedTransient: false,
+		},
+		{name: "connection closed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+			},
+			expectedTransient: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(tc.handler)
+			defer ts.Close()
+
+			_, err := NewClient(1, 1).ExecuteCall(context.Background(), ts.URL, "test", []interface{}{})
+			if err == nil {
+				t.Fatalf("Expected error was not returned")
+			}
+			_, transient := err.(*TransientError)
+			if transient != tc.expectedTransient {
+				t.Fatalf("expected and actual doesn't match, Actual was: %v, Expected was: %v", transient, tc.expectedTransient)
+			}
+		})
+	}
+}
+
+func TestExecuteCallFault(t *testing.T) {
+	faultResponse := `<?xml version="1.0" encoding="UTF-8"?>
+	<methodResponse><fault><value><struct>
+		<member><name>faultCode</name><value><int>-210</int></value></member>
+		<member><name>faultString</name><value><string>No such system - sid = 1000010000</string></value></member>
+	</struct></value></fault></methodResponse>`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, faultResponse)
+	}))
+	defer ts.Close()
+
+	_, err := NewClient(1, 1).ExecuteCall(context.Background(), ts.URL, "system.getDetails", []interface{}{"sessionKey", int64(1000010000)})
+
+	expectedFault := &FaultError{-210, "No such system - sid = 1000010000"}
+	if !reflect.DeepEqual(err, expectedFault) {
+		t.Fatalf("expected and actual doesn't match, Actual was: %v, Expected was: %v", err, expectedFault)
+	}
+}
+
+func TestExecuteCallWithTimeoutsOverride(t *testing.T) {
+	tt := []struct {
+		name           string
+		requestTimeout time.Duration
+		sleepTime      time.Duration
+		expectedError  string
+	}{
+		{name: "longer timeout", requestTimeout: 3 * time.Second, sleepTime: 1500 * time.Millisecond},
+		{name: "shorter timeout", requestTimeout: 100 * time.Millisecond, sleepTime: 500 * time.Millisecond, expectedError: "i/o timeout"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(tc.sleepTime)
+				io.WriteString(w, sampleResponse)
+			}))
+			defer ts.Close()
+
+			client := NewClient(1, 1)
+			ctx := WithTimeouts(context.Background(), 0, tc.requestTimeout)
+			_, err := client.ExecuteCall(ctx, ts.URL, "test", []interface{}{})
+
+			if tc.expectedError == "" && err != nil {
+				t.Fatalf("Unexpected error was returned: %v", err)
+			}
+			if tc.expectedError != "" && (err == nil || !strings.Contains(err.Error(), tc.expectedError)) {
+				t.Fatalf("expected and actual doesn't match, Actual was: %v, Expected was: %v", err, tc.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package uyuni
+
+import (
+	"context"
+	"math/rand"
+	"path"
+	"time"
+)
+
+//transientError is implemented by the Client errors caused by conditions which may go away if the call is retried
+type transientError interface {
+	Transient() bool
+}
+
+func isTransient(err error) bool {
+	transientErr, ok := err.(transientError)
+	return ok && transientErr.Transient()
+}
+
+//retryPolicy defines which failed calls are retried, how many times and how long to wait between attempts
+type retryPolicy struct {
+	maxRetries          int
+	initialBackoff      time.Duration
+	maxBackoff          time.Duration
+	nonRetryableMethods []string
+}
+
+//NewRetryPolicy instantiates a retry policy which retries calls failed because of transient errors up to maxRetries times.
+//The backoff starts at initialBackoff and doubles after every attempt, up to maxBackoff, with random jitter.
+//Methods matching any of the nonRetryableMethods patterns (see path.Match) are never retried, as they may not be idempotent.
+func NewRetryPolicy(maxRetries int, initialBackoff, maxBackoff time.Duration, nonRetryableMethods []string) *retryPolicy {
+	return &retryPolicy{maxRetries, initialBackoff, maxBackoff, nonRetryableMethods}
+}
+
+func (p *retryPolicy) shouldRetry(call string, attempt int, err error) bool {
+	if p == nil || attempt > p.maxRetries {
+		return false
+	}
+	if !isTransient(err) {
+		return false
+	}
+	for _, pattern := range p.nonRetryableMethods {
+		if matched, _ := path.Match(pattern, call); matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.initialBackoff
+	for i := 1; i < attempt && backoff < p.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.maxBackoff {
+		backoff = p.maxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	//wait between half and the full backoff, so the retries to a server are spread over time
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+//waitBeforeRetry sleeps for the backoff of the given attempt. It returns false if ctx was done in the meantime.
+func (p *retryPolicy) waitBeforeRetry(ctx context.Context, attempt int) bool {
+	timer := time.NewTimer(p.backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,76 @@
+package uyuni
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/uyuni/client"
+)
+
+//timeoutPolicy overrides the connect and request timeouts of the Client for some servers and methods
+type timeoutPolicy struct {
+	serverConnectTimeouts map[string]int
+	serverRequestTimeouts map[string]int
+	methodRequestTimeouts map[string]int
+}
+
+//NewTimeoutPolicy instantiates a timeout policy. Timeouts are given in seconds. Servers are identified either by ID or by FQDN,
+//and methods by name patterns (see path.Match). When several patterns match a method, the longest one is used.
+//Method timeouts take precedence over server timeouts.
+func NewTimeoutPolicy(serverConnectTimeouts, serverRequestTimeouts, methodRequestTimeouts map[string]int) *timeoutPolicy {
+	return &timeoutPolicy{serverConnectTimeouts, serverRequestTimeouts, methodRequestTimeouts}
+}
+
+//apply returns a copy of ctx carrying the timeouts for the call, if any of them is overridden
+func (p *timeoutPolicy) apply(ctx context.Context, endpoint, call string) context.Context {
+	if p == nil {
+		return ctx
+	}
+	serverKeys := []string{endpointHost(endpoint)}
+	if trace := gateway.ServerCallTraceFromContext(ctx); trace != nil && trace.ServerID != 0 {
+		serverKeys = append([]string{strconv.FormatInt(trace.ServerID, 10)}, serverKeys...)
+	}
+	connectTimeout := lookupServerTimeout(p.serverConnectTimeouts, serverKeys)
+	requestTimeout := lookupMethodTimeout(p.methodRequestTimeouts, call)
+	if requestTimeout == 0 {
+		requestTimeout = lookupServerTimeout(p.serverRequestTimeouts, serverKeys)
+	}
+	if connectTimeout == 0 && requestTimeout == 0 {
+		return ctx
+	}
+	return client.WithTimeouts(ctx, time.Duration(connectTimeout)*time.Second, time.Duration(requestTimeout)*time.Second)
+}
+
+func endpointHost(endpoint string) string {
+	parsedEndpoint, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	return parsedEndpoint.Hostname()
+}
+
+func lookupServerTimeout(timeouts map[string]int, serverKeys []string) int {
+	for _, key := range serverKeys {
+		if timeout, ok := timeouts[key]; ok {
+			return timeout
+		}
+	}
+	return 0
+}
+
+func lookupMethodTimeout(timeouts map[string]int, call string) int {
+	if timeout, ok := timeouts[call]; ok {
+		return timeout
+	}
+	timeout, longestPattern := 0, ""
+	for pattern, patternTimeout := range timeouts {
+		if matched, _ := path.Match(pattern, call); matched && len(pattern) > len(longestPattern) {
+			timeout, longestPattern = patternTimeout, pattern
+		}
+	}
+	return timeout
+}
@@ -1,5 +1,7 @@
 package uyuni
 
+import "context"
+
 //Server authenticator
 const (
 	loginPath  = "auth.login"
@@ -14,16 +16,16 @@ func NewUyuniAuthenticator(uyuniCallExecutor *uyuniCallExecutor) *uyuniAuthentic
 	return &uyuniAuthenticator{uyuniCallExecutor}
 }
 
-func (u *uyuniAuthenticator) Login(endpoint, username, password string) (string, error) {
-	response, err := u.uyuniCallExecutor.ExecuteCall(endpoint, loginPath, []interface{}{username, password})
+func (u *uyuniAuthenticator) Login(ctx context.Context, endpoint, username, password string) (string, error) {
+	response, err := u.uyuniCallExecutor.ExecuteCall(ctx, endpoint, loginPath, []interface{}{username, password})
 	if err != nil {
 		return "", err
 	}
 	return response.(string), nil
 }
 
-func (u *uyuniAuthenticator) Logout(endpoint, sessionKey string) error {
-	_, err := u.uyuniCallExecutor.ExecuteCall(endpoint, logoutPath, []interface{}{sessionKey})
+func (u *uyuniAuthenticator) Logout(ctx context.Context, endpoint, sessionKey string) error {
+	_, err := u.uyuniCallExecutor.ExecuteCall(ctx, endpoint, logoutPath, []interface{}{sessionKey})
 	if err != nil {
 		return err
 	}
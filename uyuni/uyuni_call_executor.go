@@ -1,21 +1,48 @@
 package uyuni
 
+import (
+	"context"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
+)
+
 type uyuniCallExecutor struct {
-	client Client
+	client          Client
+	retryPolicy     *retryPolicy
+	circuitBreakers *circuitBreakers
+	timeoutPolicy   *timeoutPolicy
 }
 
 type Client interface {
-	ExecuteCall(endpoint string, call string, args []interface{}) (response interface{}, err error)
+	ExecuteCall(ctx context.Context, endpoint string, call string, args []interface{}) (response interface{}, err error)
 }
 
-func NewUyuniCallExecutor(client Client) *uyuniCallExecutor {
-	return &uyuniCallExecutor{client}
+//NewUyuniCallExecutor instantiates a uyuniCallExecutor. Failed calls are retried according to retryPolicy,
+//calls to unavailable endpoints are prevented by circuitBreakers and timeouts are overridden by timeoutPolicy.
+//All of them are optional.
+func NewUyuniCallExecutor(client Client, retryPolicy *retryPolicy, circuitBreakers *circuitBreakers, timeoutPolicy *timeoutPolicy) *uyuniCallExecutor {
+	return &uyuniCallExecutor{client, retryPolicy, circuitBreakers, timeoutPolicy}
 }
 
-func (u *uyuniCallExecutor) ExecuteCall(endpoint, call string, args []interface{}) (interface{}, error) {
-	response, err := u.client.ExecuteCall(endpoint, call, args)
-	if err != nil {
-		return "", err
+func (u *uyuniCallExecutor) ExecuteCall(ctx context.Context, endpoint, call string, args []interface{}) (interface{}, error) {
+	trace := gateway.ServerCallTraceFromContext(ctx)
+	callCtx := u.timeoutPolicy.apply(ctx, endpoint, call)
+	for attempt := 1; ; attempt++ {
+		if trace != nil {
+			trace.Attempts = attempt
+		}
+		if err := u.circuitBreakers.allow(endpoint); err != nil {
+			return "", err
+		}
+		response, err := u.client.ExecuteCall(callCtx, endpoint, call, args)
+		u.circuitBreakers.recordResult(ctx, endpoint, err)
+		if err == nil {
+			return response, nil
+		}
+		if !u.retryPolicy.shouldRetry(call, attempt, err) || !u.retryPolicy.waitBeforeRetry(ctx, attempt) {
+			return "", err
+		}
+		logging.Warn(ctx, "Retrying call after failed attempt", "call", call, "endpoint", endpoint, "attempt", attempt, "error", err)
 	}
-	return response, nil
 }
@@ -0,0 +1,122 @@
+package uyuni
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/uyuni/client"
+)
+
+type mockClient struct {
+	errors []error
+	calls  int
+}
+
+func (m *mockClient) ExecuteCall(ctx context.Context, endpoint string, call string, args []interface{}) (interface{}, error) {
+	m.calls++
+	if m.calls <= len(m.errors) {
+		return nil, m.errors[m.calls-1]
+	}
+	return "success_call", nil
+}
+
+type mockTransientError struct{}
+
+func (e *mockTransientError) Error() string   { return "connection refused" }
+func (e *mockTransientError) Transient() bool { return true }
+
+func TestExecuteCallWithRetries(t *testing.T) {
+	transientErr := &mockTransientError{}
+	faultErr := errors.New("Fault(2950): Either the password or username is incorrect")
+
+	tt := []struct {
+		name             string
+		call             string
+		clientErrors     []error
+		expectedAttempts int
+		expectedErr      error
+	}{
+		{name: "no errors", call: "system.listSystems", expectedAttempts: 1},
+		{name: "transient error retried", call: "system.listSystems", clientErrors: []error{transientErr, transientErr}, expectedAttempts: 3},
+		{name: "retries exhausted", call: "system.listSystems", clientErrors: []error{transientErr, transientErr, transientErr}, expectedAttempts: 3, expectedErr: transientErr},
+		{name: "fault not retried", call: "system.listSystems", clientErrors: []error{faultErr}, expectedAttempts: 1, expectedErr: faultErr},
+		{name: "denylisted method not retried", call: "system.scheduleReboot", clientErrors: []error{transientErr}, expectedAttempts: 1, expectedErr: transientErr},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &mockClient{errors: tc.clientErrors}
+			executor := NewUyuniCallExecutor(client, NewRetryPolicy(2, time.Millisecond, 2*time.Millisecond, []string{"system.schedule*"}), nil, nil)
+
+			trace := &gateway.ServerCallTrace{}
+			_, err := executor.ExecuteCall(gateway.WithServerCallTrace(context.Background(), trace), "endpoint", tc.call, []interface{}{})
+
+			if err != tc.expectedErr {
+				t.Fatalf("expected and actual doesn't match, Actual was: %v, Expected was: %v", err, tc.expectedErr)
+			}
+			if client.calls != tc.expectedAttempts || trace.Attempts != tc.expectedAttempts {
+				t.Fatalf("expected and actual doesn't match, Actual was: %v calls and %v traced attempts, Expected was: %v", client.calls, trace.Attempts, tc.expectedAttempts)
+			}
+		})
+	}
+}
+
+func TestExecuteCallRetryAbortedByContext(t *testing.T) {
+	client := &mockClient{errors: []error{&mockTransientError{}}}
+	executor := NewUyuniCallExecutor(client, NewRetryPolicy(2, time.Minute, time.Minute, nil), nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := executor.ExecuteCall(ctx, "endpoint", "system.listSystems", []interface{}{})
+
+	if err == nil || client.calls != 1 {
+		t.Fatalf("expected a single failed call, Actual was: %v calls, error %v", client.calls, err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("backoff was not aborted when the context was done")
+	}
+}
+
+func TestTimeoutPolicy(t *testing.T) {
+	timeoutPolicy := NewTimeoutPolicy(
+		map[string]int{"1000010000": 30, "wan-server.example.com": 20},
+		map[string]int{"1000010000": 300, "wan-server.example.com": 200},
+		map[string]int{"system.listSystems": 600, "system.list*": 120, "*": 60},
+	)
+
+	tt := []struct {
+		name                   string
+		serverID               int64
+		endpoint               string
+		call                   string
+		expectedConnectTimeout time.Duration
+		expectedRequestTimeout time.Duration
+	}{
+		{name: "server ID", serverID: 1000010000, endpoint: "http://wan-server.example.com/rpc/api", call: "auth.login",
+			expectedConnectTimeout: 30 * time.Second, expectedRequestTimeout: 60 * time.Second},
+		{name: "server FQDN", endpoint: "http://wan-server.example.com/rpc/api", call: "auth.login",
+			expectedConnectTimeout: 20 * time.Second, expectedRequestTimeout: 60 * time.Second},
+		{name: "exact method", serverID: 1000010000, endpoint: "http://server.example.com/rpc/api", call: "system.listSystems",
+			expectedConnectTimeout: 30 * time.Second, expectedRequestTimeout: 600 * time.Second},
+		{name: "longest method pattern", endpoint: "http://server.example.com/rpc/api", call: "system.listUserSystems",
+			expectedRequestTimeout: 120 * time.Second},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := gateway.WithServerCallTrace(context.Background(), &gateway.ServerCallTrace{ServerID: tc.serverID})
+			expectedCtx := client.WithTimeouts(ctx, tc.expectedConnectTimeout, tc.expectedRequestTimeout)
+
+			callCtx := timeoutPolicy.apply(ctx, tc.endpoint, tc.call)
+
+			if !reflect.DeepEqual(callCtx, expectedCtx) {
+				t.Fatalf("expected and actual doesn't match, Actual was: %v, Expected was: %v", callCtx, expectedCtx)
+			}
+		})
+	}
+}
@@ -1,10 +1,11 @@
 package uyuni
 
 import (
+	"context"
 	"errors"
-	"log"
 
 	"github.com/uyuni-project/hub-xmlrpc-api/gateway"
+	"github.com/uyuni-project/hub-xmlrpc-api/logging"
 )
 
 const (
@@ -25,14 +26,14 @@ func NewUyuniTopologyInfoRetriever(uyuniCallExecutor *uyuniCallExecutor, useSSL
 	return &uyuniTopologyInfoRetriever{uyuniCallExecutor, useSSL}
 }
 
-func (h *uyuniTopologyInfoRetriever) RetrieveUserServerIDs(endpoint, sessionKey, username string) ([]int64, error) {
-	userServers, err := h.uyuniCallExecutor.ExecuteCall(endpoint, listUserSystemsPath, []interface{}{sessionKey, username})
+func (h *uyuniTopologyInfoRetriever) RetrieveUserServerIDs(ctx context.Context, endpoint, sessionKey, username string) ([]int64, error) {
+	userServers, err := h.uyuniCallExecutor.ExecuteCall(ctx, endpoint, listUserSystemsPath, []interface{}{sessionKey, username})
 	if err != nil {
-		log.Printf("Error ocurred while trying to login into the user systems: %v", err)
+		logging.Error(ctx, "Error ocurred while trying to login into the user systems", "error", err)
 		return nil, err
 	}
 
-	allServers, err := h.ListServerIDs(endpoint, sessionKey)
+	allServers, err := h.ListServerIDs(ctx, endpoint, sessionKey)
 	if err != nil {
 		return nil, err
 	}
@@ -59,18 +60,18 @@ func contains(s []int64, v int64) bool {
 	return false
 }
 
-func (h *uyuniTopologyInfoRetriever) ListServerIDs(endpoint, sessionKey string) ([]int64, error) {
-	systemList, err := h.uyuniCallExecutor.ExecuteCall(endpoint, listSystemsWithEntitlementPath, []interface{}{sessionKey, peripheralServerEntitlement})
+func (h *uyuniTopologyInfoRetriever) ListServerIDs(ctx context.Context, endpoint, sessionKey string) ([]int64, error) {
+	systemList, err := h.uyuniCallExecutor.ExecuteCall(ctx, endpoint, listSystemsWithEntitlementPath, []interface{}{sessionKey, peripheralServerEntitlement})
 	if err != nil {
-		log.Printf("Error occured while retrieving the list of serverIDs: %v", err)
+		logging.Error(ctx, "Error occured while retrieving the list of serverIDs", "error", err)
 		return nil, err
 	}
 	systemsSlice := systemList.([]interface{})
 	if len(systemsSlice) == 0 {
 	        // No entitled servers - fallback to full list, for legacy HUB server
-		systemList, err = h.uyuniCallExecutor.ExecuteCall(endpoint, listSystemsPath, []interface{}{sessionKey})
+		systemList, err = h.uyuniCallExecutor.ExecuteCall(ctx, endpoint, listSystemsPath, []interface{}{sessionKey})
 		if err != nil {
-			log.Printf("Error occured while retrieving the list of serverIDs: %v", err)
+			logging.Error(ctx, "Error occured while retrieving the list of serverIDs", "error", err)
 			return nil, err
 		}
 		systemsSlice = systemList.([]interface{})
@@ -83,11 +84,11 @@ func (h *uyuniTopologyInfoRetriever) ListServerIDs(endpoint, sessionKey string)
 	return systemIDs, nil
 }
 
-func (h *uyuniTopologyInfoRetriever) RetrieveServerAPIEndpoints(endpoint, sessionKey string, serverIDs []int64) (*gateway.RetrieveServerAPIEndpointsResponse, error) {
+func (h *uyuniTopologyInfoRetriever) RetrieveServerAPIEndpoints(ctx context.Context, endpoint, sessionKey string, serverIDs []int64) (*gateway.RetrieveServerAPIEndpointsResponse, error) {
 	serverAPIEndpointByServer := make(map[int64]string)
 	failedServers := make(map[int64]string)
 	for _, serverID := range serverIDs {
-		serverAPIEndpoint, err := h.retrieveServerAPIEndpoint(endpoint, sessionKey, serverID)
+		serverAPIEndpoint, err := h.retrieveServerAPIEndpoint(ctx, endpoint, sessionKey, serverID)
 		if err != nil {
 			failedServers[serverID] = err.Error()
 		} else {
@@ -97,29 +98,29 @@ func (h *uyuniTopologyInfoRetriever) RetrieveServerAPIEndpoints(endpoint, sessio
 	return &gateway.RetrieveServerAPIEndpointsResponse{serverAPIEndpointByServer, failedServers}, nil
 }
 
-func (h *uyuniTopologyInfoRetriever) retrieveServerAPIEndpoint(endpoint, sessionKey string, serverID int64) (string, error) {
+func (h *uyuniTopologyInfoRetriever) retrieveServerAPIEndpoint(ctx context.Context, endpoint, sessionKey string, serverID int64) (string, error) {
 	//if more than one FQDN is retrieve, we keep the first one and discard the rest
-	response, err := h.uyuniCallExecutor.ExecuteCall(endpoint, listSystemFQDNsPath, []interface{}{sessionKey, serverID})
+	response, err := h.uyuniCallExecutor.ExecuteCall(ctx, endpoint, listSystemFQDNsPath, []interface{}{sessionKey, serverID})
 	if err != nil {
-		log.Printf("Error ocurred when retrieving the system Fqdns for serverID: %v, error:%v", serverID, err)
+		logging.Error(ctx, "Error ocurred when retrieving the system Fqdns", "server_id", serverID, "error", err)
 		return "", err
 	}
-	return parseFQDN(response, h.useSSL)
+	return parseFQDN(ctx, serverID, response, h.useSSL)
 }
 
-func parseFQDN(fqdnResponse interface{}, useSSL bool) (string, error) {
+func parseFQDN(ctx context.Context, serverID int64, fqdnResponse interface{}, useSSL bool) (string, error) {
 	fqdns, ok := fqdnResponse.([]interface{})
 	if !ok {
-		log.Printf("Error ocurred when parsing the FQDNs of peripheral servers")
+		logging.Error(ctx, "Error ocurred when parsing the FQDNs of peripheral servers", "server_id", serverID)
 		return "", errors.New("Error ocurred when parsing the FQDNs of peripheral servers")
 	}
 	if len(fqdns) < 1 {
-		log.Printf("Error ocurred when retrieving the FQDNs of peripheral servers: no FQDN found")
+		logging.Error(ctx, "Error ocurred when retrieving the FQDNs of peripheral servers: no FQDN found", "server_id", serverID)
 		return "", errors.New("Error ocurred when retrieving the FQDNs of peripheral servers: no FQDN found")
 	}
 	firstFqdn, ok := fqdns[0].(string)
 	if !ok {
-		log.Printf("Error ocurred when parsing the FQDNs of peripheral servers")
+		logging.Error(ctx, "Error ocurred when parsing the FQDNs of peripheral servers", "server_id", serverID)
 		return "", errors.New("Error ocurred when parsing the FQDNs of peripheral servers")
 	}
 	protocol := "http://"